@@ -0,0 +1,28 @@
+// Package api embeds the OpenAPI description of the analyzer service and its documentation page
+package api
+
+import (
+	_ "embed"
+	"encoding/json"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Spec is the OpenAPI document in YAML
+//
+//go:embed api.yaml
+var Spec []byte
+
+// DocsPage renders Spec with Swagger UI
+//
+//go:embed docs.html
+var DocsPage []byte
+
+// SpecJSON converts Spec to JSON
+func SpecJSON() ([]byte, error) {
+	var spec any
+	if err := yaml.Unmarshal(Spec, &spec); err != nil {
+		return nil, err
+	}
+	return json.Marshal(spec)
+}
@@ -0,0 +1,220 @@
+package analyzer
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Codes of the accessibility problems found on the page
+const (
+	AccessibilityIssueImageMissingAlt    = "image_missing_alt"
+	AccessibilityIssueInputMissingLabel  = "input_missing_label"
+	AccessibilityIssueLinkMissingText    = "link_missing_text"
+	AccessibilityIssueMissingLang        = "missing_lang"
+	AccessibilityIssueDuplicateID        = "duplicate_id"
+	AccessibilityIssueButtonMissingText  = "button_missing_text"
+	AccessibilityIssueInvalidARIARole    = "invalid_aria_role"
+	AccessibilityIssueInvalidARIAAttr    = "invalid_aria_attribute"
+	AccessibilityIssueInvalidARIAValue   = "invalid_aria_value"
+	AccessibilityIssueMissingLabelTarget = "missing_labelledby_target"
+)
+
+// AccessibilityAudit lists the common WCAG failures found on the page
+type AccessibilityAudit struct {
+	Issues []AccessibilityIssue `json:"issues"`
+}
+
+// AccessibilityIssue is an accessibility problem of a single element
+type AccessibilityIssue struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Element is a CSS-like path to the offending element
+	Element string `json:"element"`
+}
+
+// ariaRoles are the roles of WAI-ARIA 1.2 authors may use, abstract roles excluded
+var ariaRoles = toSet(
+	"alert", "alertdialog", "application", "article", "banner", "blockquote", "button", "caption",
+	"cell", "checkbox", "code", "columnheader", "combobox", "complementary", "contentinfo",
+	"definition", "deletion", "dialog", "directory", "document", "emphasis", "feed", "figure",
+	"form", "generic", "grid", "gridcell", "group", "heading", "img", "insertion", "link", "list",
+	"listbox", "listitem", "log", "main", "marquee", "math", "meter", "menu", "menubar", "menuitem",
+	"menuitemcheckbox", "menuitemradio", "navigation", "none", "note", "option", "paragraph",
+	"presentation", "progressbar", "radio", "radiogroup", "region", "row", "rowgroup", "rowheader",
+	"scrollbar", "search", "searchbox", "separator", "slider", "spinbutton", "status", "strong",
+	"subscript", "superscript", "switch", "tab", "table", "tablist", "tabpanel", "term", "textbox",
+	"time", "timer", "toolbar", "tooltip", "tree", "treegrid", "treeitem",
+	"graphics-document", "graphics-object", "graphics-symbol",
+)
+
+// ariaAttributes are the states and properties of WAI-ARIA 1.2
+var ariaAttributes = toSet(
+	"aria-activedescendant", "aria-atomic", "aria-autocomplete", "aria-braillelabel",
+	"aria-brailleroledescription", "aria-busy", "aria-checked", "aria-colcount", "aria-colindex",
+	"aria-colindextext", "aria-colspan", "aria-controls", "aria-current", "aria-describedby",
+	"aria-description", "aria-details", "aria-disabled", "aria-dropeffect", "aria-errormessage",
+	"aria-expanded", "aria-flowto", "aria-grabbed", "aria-haspopup", "aria-hidden", "aria-invalid",
+	"aria-keyshortcuts", "aria-label", "aria-labelledby", "aria-level", "aria-live", "aria-modal",
+	"aria-multiline", "aria-multiselectable", "aria-orientation", "aria-owns", "aria-placeholder",
+	"aria-posinset", "aria-pressed", "aria-readonly", "aria-relevant", "aria-required",
+	"aria-roledescription", "aria-rowcount", "aria-rowindex", "aria-rowindextext", "aria-rowspan",
+	"aria-selected", "aria-setsize", "aria-sort", "aria-valuemax", "aria-valuemin", "aria-valuenow",
+	"aria-valuetext",
+)
+
+// ariaBooleans are the ARIA attributes that only take true or false
+var ariaBooleans = toSet(
+	"aria-atomic", "aria-busy", "aria-disabled", "aria-modal", "aria-multiline",
+	"aria-multiselectable", "aria-readonly", "aria-required",
+)
+
+// unlabeledInputTypes are input types that need no label, as they are hidden or labeled by their value
+var unlabeledInputTypes = toSet("hidden", "submit", "reset", "button", "image")
+
+// getAccessibilityAudit checks the document for images without alt text, unlabeled form fields,
+// links and buttons without text, a missing page language, duplicate ids and invalid ARIA
+func getAccessibilityAudit(doc *html.Node) AccessibilityAudit {
+	audit := AccessibilityAudit{Issues: []AccessibilityIssue{}}
+	report := func(n *html.Node, code, format string, args ...any) {
+		audit.Issues = append(audit.Issues, AccessibilityIssue{Code: code, Message: fmt.Sprintf(format, args...), Element: elementPath(n)})
+	}
+
+	// ids and label targets must be known before checking the elements that reference them
+	ids := make(map[string]*html.Node)
+	labelFor := make(map[string]bool)
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			if id := attrValue(n, "id"); id != "" {
+				if first, seen := ids[id]; seen {
+					report(n, AccessibilityIssueDuplicateID, "the id %q is already used by %s", id, elementPath(first))
+				} else {
+					ids[id] = n
+				}
+			}
+			if n.Data == "label" {
+				if target := attrValue(n, "for"); target != "" {
+					labelFor[target] = true
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			collect(c)
+		}
+	}
+	collect(doc)
+
+	// hasName reports whether n is named by aria-label, aria-labelledby or title
+	hasName := func(n *html.Node) bool {
+		if strings.TrimSpace(attrValue(n, "aria-label")) != "" || strings.TrimSpace(attrValue(n, "title")) != "" {
+			return true
+		}
+		for _, id := range strings.Fields(attrValue(n, "aria-labelledby")) {
+			if ids[id] != nil {
+				return true
+			}
+		}
+		return false
+	}
+
+	var traverse func(n *html.Node, hidden, inLabel bool)
+	traverse = func(n *html.Node, hidden, inLabel bool) {
+		if n.Type != html.ElementNode {
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				traverse(c, hidden, inLabel)
+			}
+			return
+		}
+
+		checkARIA(n, ids, report)
+		// elements hidden from assistive technology need no text alternatives
+		hidden = hidden || strings.EqualFold(attrValue(n, "aria-hidden"), "true")
+		roles := strings.Fields(strings.ToLower(attrValue(n, "role")))
+		presentational := slices.Contains(roles, "presentation") || slices.Contains(roles, "none")
+		inputType := strings.ToLower(attrValue(n, "type"))
+
+		switch {
+		case n.Data == "html":
+			if strings.TrimSpace(attrValue(n, "lang")) == "" && strings.TrimSpace(attrValue(n, "xml:lang")) == "" {
+				report(n, AccessibilityIssueMissingLang, "the page does not declare its language in the lang attribute of the html element")
+			}
+		case hidden:
+		case n.Data == "img" || (n.Data == "input" && inputType == "image"):
+			if _, ok := getAttr(n, "alt"); !ok && !presentational && !hasName(n) {
+				report(n, AccessibilityIssueImageMissingAlt, "the image %s has no alt text", attrValue(n, "src"))
+			}
+		case n.Data == "input" || n.Data == "select" || n.Data == "textarea":
+			if n.Data == "input" && unlabeledInputTypes[inputType] {
+				if inputType == "button" && strings.TrimSpace(attrValue(n, "value")) == "" && !hasName(n) {
+					report(n, AccessibilityIssueButtonMissingText, "the button has no text")
+				}
+				break
+			}
+			if !inLabel && !labelFor[attrValue(n, "id")] && !hasName(n) {
+				report(n, AccessibilityIssueInputMissingLabel, "the %s field %q has no label", n.Data, attrValue(n, "name"))
+			}
+		case n.Data == "a":
+			if _, ok := getAttr(n, "href"); ok && textContent(n) == "" && !hasName(n) {
+				report(n, AccessibilityIssueLinkMissingText, "the link to %s has no text", attrValue(n, "href"))
+			}
+		case n.Data == "button":
+			if textContent(n) == "" && !hasName(n) {
+				report(n, AccessibilityIssueButtonMissingText, "the button has no text")
+			}
+		}
+
+		inLabel = inLabel || n.Data == "label"
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			traverse(c, hidden, inLabel)
+		}
+	}
+	traverse(doc, false, false)
+
+	return audit
+}
+
+// checkARIA reports unknown roles, unknown aria-* attributes, invalid boolean values
+// and aria-labelledby references to missing elements
+func checkARIA(n *html.Node, ids map[string]*html.Node, report func(n *html.Node, code, format string, args ...any)) {
+	if role, ok := getAttr(n, "role"); ok {
+		// the first known role of a fallback list is used, so only unknown roles are reported
+		for _, r := range strings.Fields(strings.ToLower(role)) {
+			if !ariaRoles[r] && !strings.HasPrefix(r, "doc-") {
+				report(n, AccessibilityIssueInvalidARIARole, "%q is not a valid ARIA role", r)
+			}
+		}
+	}
+	for _, attr := range n.Attr {
+		key := strings.ToLower(attr.Key)
+		if !strings.HasPrefix(key, "aria-") {
+			continue
+		}
+		value := strings.ToLower(strings.TrimSpace(attr.Val))
+		switch {
+		case !ariaAttributes[key]:
+			report(n, AccessibilityIssueInvalidARIAAttr, "%s is not a valid ARIA attribute", key)
+		case ariaBooleans[key] && value != "true" && value != "false":
+			report(n, AccessibilityIssueInvalidARIAValue, "%s must be true or false, not %q", key, attr.Val)
+		case key == "aria-hidden" && value != "true" && value != "false" && value != "undefined":
+			report(n, AccessibilityIssueInvalidARIAValue, "%s must be true or false, not %q", key, attr.Val)
+		case key == "aria-labelledby":
+			for _, id := range strings.Fields(attr.Val) {
+				if ids[id] == nil {
+					report(n, AccessibilityIssueMissingLabelTarget, "aria-labelledby references the missing id %q", id)
+				}
+			}
+		}
+	}
+}
+
+// toSet returns a lookup table of values
+func toSet(values ...string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
@@ -0,0 +1,109 @@
+package analyzer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+// Table-driven tests for getAccessibilityAudit
+func TestGetAccessibilityAudit(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		expected []AccessibilityIssue
+	}{
+		{
+			name: "Accessible",
+			html: `<html lang="en"><body>
+				<img src="/logo.png" alt="Example"><img src="/spacer.gif" alt="">
+				<label for="email">Email</label><input id="email" name="email">
+				<label>Password <input type="password" name="password"></label>
+				<input type="submit"><input type="hidden" name="token">
+				<a href="/"><img src="/home.png" alt="Home"></a>
+				<button aria-label="Close"><svg aria-hidden="true"></svg></button>
+				<nav role="navigation" aria-label="Main"></nav>
+			</body></html>`,
+			expected: []AccessibilityIssue{},
+		},
+		{
+			name: "MissingLang",
+			html: `<html><body><p>Text</p></body></html>`,
+			expected: []AccessibilityIssue{
+				{Code: AccessibilityIssueMissingLang, Message: "the page does not declare its language in the lang attribute of the html element", Element: "html"},
+			},
+		},
+		{
+			name: "ImageWithoutAlt",
+			html: `<html lang="en"><body><div><img src="/a.png"><img src="/b.png" role="presentation"><img src="/c.png"></div></body></html>`,
+			expected: []AccessibilityIssue{
+				{Code: AccessibilityIssueImageMissingAlt, Message: "the image /a.png has no alt text", Element: "html > body > div > img:nth-of-type(1)"},
+				{Code: AccessibilityIssueImageMissingAlt, Message: "the image /c.png has no alt text", Element: "html > body > div > img:nth-of-type(3)"},
+			},
+		},
+		{
+			name: "InputsWithoutLabels",
+			html: `<html lang="en"><body><form id="signup">
+				<input name="email" placeholder="Email">
+				<input name="phone" aria-label="Phone">
+				<select name="country"></select>
+			</form></body></html>`,
+			expected: []AccessibilityIssue{
+				{Code: AccessibilityIssueInputMissingLabel, Message: `the input field "email" has no label`, Element: "html > body > form#signup > input:nth-of-type(1)"},
+				{Code: AccessibilityIssueInputMissingLabel, Message: `the select field "country" has no label`, Element: "html > body > form#signup > select"},
+			},
+		},
+		{
+			name: "EmptyLinksAndButtons",
+			html: `<html lang="en"><body>
+				<a href="/next"><i class="icon"></i></a>
+				<a href="/hidden" aria-hidden="true"></a>
+				<button><span></span></button>
+				<input type="button">
+			</body></html>`,
+			expected: []AccessibilityIssue{
+				{Code: AccessibilityIssueLinkMissingText, Message: "the link to /next has no text", Element: "html > body > a:nth-of-type(1)"},
+				{Code: AccessibilityIssueButtonMissingText, Message: "the button has no text", Element: "html > body > button"},
+				{Code: AccessibilityIssueButtonMissingText, Message: "the button has no text", Element: "html > body > input"},
+			},
+		},
+		{
+			name: "DuplicateIDs",
+			html: `<html lang="en"><body><div id="main"></div><p id="main">Text</p></body></html>`,
+			expected: []AccessibilityIssue{
+				{Code: AccessibilityIssueDuplicateID, Message: `the id "main" is already used by html > body > div#main`, Element: "html > body > p#main"},
+			},
+		},
+		{
+			name: "InvalidARIA",
+			html: `<html lang="en"><body>
+				<div role="buton">Save</div>
+				<div role="widget"></div>
+				<div aria-lable="Menu" aria-hidden="yes"></div>
+				<div aria-disabled="disabled"></div>
+				<input name="q" aria-labelledby="missing">
+			</body></html>`,
+			expected: []AccessibilityIssue{
+				{Code: AccessibilityIssueInvalidARIARole, Message: `"buton" is not a valid ARIA role`, Element: "html > body > div:nth-of-type(1)"},
+				{Code: AccessibilityIssueInvalidARIARole, Message: `"widget" is not a valid ARIA role`, Element: "html > body > div:nth-of-type(2)"},
+				{Code: AccessibilityIssueInvalidARIAAttr, Message: "aria-lable is not a valid ARIA attribute", Element: "html > body > div:nth-of-type(3)"},
+				{Code: AccessibilityIssueInvalidARIAValue, Message: `aria-hidden must be true or false, not "yes"`, Element: "html > body > div:nth-of-type(3)"},
+				{Code: AccessibilityIssueInvalidARIAValue, Message: `aria-disabled must be true or false, not "disabled"`, Element: "html > body > div:nth-of-type(4)"},
+				{Code: AccessibilityIssueMissingLabelTarget, Message: `aria-labelledby references the missing id "missing"`, Element: "html > body > input"},
+				{Code: AccessibilityIssueInputMissingLabel, Message: `the input field "q" has no label`, Element: "html > body > input"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, _ := html.Parse(strings.NewReader(tt.html))
+			result := getAccessibilityAudit(doc)
+			if !reflect.DeepEqual(result.Issues, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, result.Issues)
+			}
+		})
+	}
+}
@@ -1,52 +1,330 @@
 package analyzer
 
 import (
+	"bufio"
+	"context"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/url"
-	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"golang.org/x/net/html"
+	"golang.org/x/time/rate"
 )
 
 type AnalysisResult struct {
-	HTMLVersion          string
-	PageTitle            string
-	Headings             map[string]int
-	NumInternalLinks     int
-	NumExternalLinks     int
-	NumInaccessibleLinks int
-	IsContainLoginForm   bool
+	// ContentType is the media type of the page, text/html or application/xhtml+xml
+	ContentType      string              `json:"contentType"`
+	Encoding         EncodingInfo        `json:"encoding"`
+	Size             SizeInfo            `json:"size"`
+	HTMLVersion      string              `json:"htmlVersion"`
+	Doctype          DoctypeInfo         `json:"doctype"`
+	PageTitle        string              `json:"pageTitle"`
+	SEO              SEOAudit            `json:"seo"`
+	SecurityHeaders  SecurityHeaders     `json:"securityHeaders"`
+	Social           SocialMeta          `json:"social"`
+	StructuredData   StructuredData      `json:"structuredData"`
+	Accessibility    AccessibilityAudit  `json:"accessibility"`
+	Images           []Image             `json:"images"`
+	Resources        ResourceInventory   `json:"resources"`
+	ThirdParties     ThirdPartyInventory `json:"thirdParties"`
+	MixedContent     MixedContent        `json:"mixedContent"`
+	Headings         map[string]int      `json:"headings"`
+	Outline          Outline             `json:"outline"`
+	NumInternalLinks int                 `json:"internalLinks"`
+	NumExternalLinks int                 `json:"externalLinks"`
+	// LinkScopes splits the links into same host, same site and external ones
+	LinkScopes           LinkScopes `json:"linkScopes"`
+	NumInaccessibleLinks int        `json:"inaccessibleLinks"`
+	// NumUncheckedLinks counts the links, images, scripts and stylesheets left unchecked
+	// because the link check limit was spent or the analysis ended first
+	NumUncheckedLinks  int  `json:"uncheckedLinks"`
+	IsContainLoginForm bool `json:"containsLoginForm"`
+	// AuthForms lists the login, signup and password reset forms
+	AuthForms []AuthForm `json:"authForms"`
+	// Forms lists every form with its fields
+	Forms []Form `json:"forms"`
 }
 
 // AnalyzeURLFunc defines the type for the function used to analyze URLs
-type AnalyzeURLFunc func(string) (AnalysisResult, error)
+type AnalyzeURLFunc func(context.Context, string) (AnalysisResult, error)
+
+// Options controls how the analyzer fetches pages and checks links
+type Options struct {
+	// UserAgent is sent with every request the analyzer makes
+	UserAgent string
+	// FetchTimeout bounds fetching the analyzed page
+	FetchTimeout time.Duration
+	// LinkCheckTimeout bounds checking a single link
+	LinkCheckTimeout time.Duration
+	// MaxLinkChecks is the number of links, images, scripts and stylesheets an analysis checks concurrently
+	MaxLinkChecks int
+	// HostRate limits the requests per second to a single host, zero disables the limit
+	HostRate float64
+	// HostBurst is the number of requests to a host allowed at once
+	HostBurst int
+	// HostMaxWait is how long fetching the analyzed page may wait for its host's
+	// rate limit before failing with a RateLimitError
+	HostMaxWait time.Duration
+	// MaxDocumentSize is the largest page body accepted, in bytes as transferred
+	MaxDocumentSize int64
+	// MaxDecompressedSize is the largest page accepted after decompression, in bytes
+	MaxDecompressedSize int64
+	// MaxProbeBytes limits the bytes all probes of an analysis download together
+	MaxProbeBytes int64
+	// TrackerRules classifies third-party domains, nil means DefaultTrackerRules
+	TrackerRules []TrackerRule
+	// InternalHosts lists further hosts whose links count as internal, together with their subdomains.
+	// They are not reported as third parties either.
+	InternalHosts []string
+}
+
+// DefaultOptions returns the options used by AnalyzeURL
+func DefaultOptions() Options {
+	return Options{
+		UserAgent:           "webpage-analyzer/1.0",
+		FetchTimeout:        30 * time.Second,
+		LinkCheckTimeout:    10 * time.Second,
+		MaxLinkChecks:       10,
+		HostRate:            5,
+		HostBurst:           20,
+		HostMaxWait:         5 * time.Second,
+		MaxDocumentSize:     5 << 20,
+		MaxDecompressedSize: 20 << 20,
+		MaxProbeBytes:       20 << 20,
+	}
+}
+
+// Analyzer analyzes web pages using a fixed set of options
+type Analyzer struct {
+	opts   Options
+	client *http.Client
+	// hosts rate limits requests per target host, nil when unlimited
+	hosts *hostLimiters
+	// trackers classifies third-party domains
+	trackers trackerMatcher
+}
+
+// New returns an Analyzer configured with opts
+func New(opts Options) *Analyzer {
+	if opts.MaxLinkChecks < 1 {
+		opts.MaxLinkChecks = 1
+	}
+	// pages are never read without a size limit
+	if opts.MaxDocumentSize <= 0 {
+		opts.MaxDocumentSize = DefaultOptions().MaxDocumentSize
+	}
+	if opts.MaxDecompressedSize <= 0 {
+		opts.MaxDecompressedSize = DefaultOptions().MaxDecompressedSize
+	}
+	if opts.MaxProbeBytes <= 0 {
+		opts.MaxProbeBytes = DefaultOptions().MaxProbeBytes
+	}
+	if opts.TrackerRules == nil {
+		opts.TrackerRules = DefaultTrackerRules()
+	}
+	a := &Analyzer{
+		opts:     opts,
+		client:   &http.Client{},
+		trackers: newTrackerMatcher(opts.TrackerRules),
+	}
+	if opts.HostRate > 0 {
+		a.hosts = newHostLimiters(rate.Limit(opts.HostRate), max(opts.HostBurst, 1))
+	}
+	return a
+}
+
+var defaultAnalyzer = New(DefaultOptions())
+
+type linkCheckLimitKey struct{}
+
+// WithLinkCheckLimit returns a context that limits an analysis to checking at most limit links.
+// A limit of zero or less means every link is checked.
+func WithLinkCheckLimit(ctx context.Context, limit int) context.Context {
+	return context.WithValue(ctx, linkCheckLimitKey{}, limit)
+}
+
+// LinkCheckLimit returns the link check limit carried by ctx, or zero when there is none
+func LinkCheckLimit(ctx context.Context) int {
+	limit, _ := ctx.Value(linkCheckLimitKey{}).(int)
+	return limit
+}
+
+type linkCheckBudgetKey struct{}
+
+// linkCheckBudget is the number of requests an analysis may still send to check links,
+// images, scripts and stylesheets, how many it sends at once and how many bytes probes
+// may still download. Every outbound check of an analysis draws from it, and it counts
+// the checks left out.
+type linkCheckBudget struct {
+	// unlimited is set when ctx carries no link check limit
+	unlimited bool
+	remaining atomic.Int64
+	skipped   atomic.Int64
+	bytes     atomic.Int64
+	// sem holds a token for every check in flight
+	sem chan struct{}
+}
+
+// newLinkCheckBudget returns a budget of limit checks, unlimited when limit is zero or less
+func (a *Analyzer) newLinkCheckBudget(limit int) *linkCheckBudget {
+	budget := &linkCheckBudget{unlimited: limit <= 0, sem: make(chan struct{}, a.opts.MaxLinkChecks)}
+	budget.remaining.Store(int64(limit))
+	budget.bytes.Store(a.opts.MaxProbeBytes)
+	return budget
+}
+
+// withLinkCheckBudget returns a context carrying a budget of the link check limit of ctx
+func (a *Analyzer) withLinkCheckBudget(ctx context.Context) (context.Context, *linkCheckBudget) {
+	budget := a.newLinkCheckBudget(LinkCheckLimit(ctx))
+	return context.WithValue(ctx, linkCheckBudgetKey{}, budget), budget
+}
 
+// budgetFrom returns the budget of the analysis ctx belongs to, or an unlimited budget
+// of its own outside of an analysis
+func (a *Analyzer) budgetFrom(ctx context.Context) *linkCheckBudget {
+	if budget, ok := ctx.Value(linkCheckBudgetKey{}).(*linkCheckBudget); ok {
+		return budget
+	}
+	return a.newLinkCheckBudget(0)
+}
+
+// take uses one request of the budget and reports false once it is spent
+func (b *linkCheckBudget) take() bool {
+	return b.unlimited || b.remaining.Add(-1) >= 0
+}
+
+// skip records a check left out because the budget was spent or the analysis ended first
+func (b *linkCheckBudget) skip() {
+	b.skipped.Add(1)
+}
+
+// numSkipped returns the number of checks left out
+func (b *linkCheckBudget) numSkipped() int {
+	return int(b.skipped.Load())
+}
+
+// acquire waits until fewer than MaxLinkChecks checks of the analysis are in flight
+func (b *linkCheckBudget) acquire() {
+	b.sem <- struct{}{}
+}
+
+// release ends a check started with acquire
+func (b *linkCheckBudget) release() {
+	<-b.sem
+}
+
+// hasBytes reports whether probes may still download bytes
+func (b *linkCheckBudget) hasBytes() bool {
+	return b.bytes.Load() > 0
+}
+
+// discard reads up to limit bytes of r as long as the bytes probes may download last,
+// and returns how many bytes it read
+func (b *linkCheckBudget) discard(r io.Reader, limit int64) int64 {
+	r = io.LimitReader(r, limit)
+	buf := make([]byte, 32<<10)
+	var n int64
+	for b.hasBytes() {
+		m, err := r.Read(buf)
+		n += int64(m)
+		b.bytes.Add(-int64(m))
+		if err != nil {
+			break
+		}
+	}
+	return n
+}
+
+// AnalyzeURL analyzes the page at urlStr with the default options
 func AnalyzeURL(urlStr string) (AnalysisResult, error) {
-	resp, err := http.Get(urlStr)
+	return defaultAnalyzer.AnalyzeURL(context.Background(), urlStr)
+}
+
+// AnalyzeURLContext analyzes the page at urlStr with the default options,
+// aborting the page fetch and the link checks once ctx is cancelled
+func AnalyzeURLContext(ctx context.Context, urlStr string) (AnalysisResult, error) {
+	return defaultAnalyzer.AnalyzeURL(ctx, urlStr)
+}
+
+// newRequest builds a request carrying the configured user agent
+func (a *Analyzer) newRequest(ctx context.Context, method, urlStr string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, urlStr, nil)
+	if err != nil {
+		return nil, err
+	}
+	if a.opts.UserAgent != "" {
+		req.Header.Set("User-Agent", a.opts.UserAgent)
+	}
+	return req, nil
+}
+
+// AnalyzeURL analyzes the page at urlStr, aborting the page fetch and the
+// link checks once ctx is cancelled
+func (a *Analyzer) AnalyzeURL(ctx context.Context, urlStr string) (AnalysisResult, error) {
+	baseURL, err := url.Parse(urlStr)
 	if err != nil {
+		return AnalysisResult{}, invalidURLError(err)
+	}
+	if baseURL.Scheme != "http" && baseURL.Scheme != "https" {
+		return AnalysisResult{}, invalidURLError(fmt.Errorf("unsupported scheme %q", baseURL.Scheme))
+	}
+	if baseURL.Host == "" {
+		return AnalysisResult{}, invalidURLError(errors.New("missing host"))
+	}
+
+	if err := a.hosts.wait(ctx, urlStr, a.opts.HostMaxWait); err != nil {
 		return AnalysisResult{}, err
 	}
+
+	fetchCtx, cancel := withTimeout(ctx, a.opts.FetchTimeout)
+	defer cancel()
+
+	req, err := a.newRequest(fetchCtx, http.MethodGet, urlStr)
+	if err != nil {
+		return AnalysisResult{}, invalidURLError(err)
+	}
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return AnalysisResult{}, classifyFetchError(err)
+	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return AnalysisResult{}, errors.New("Error fetching the URL: " + http.StatusText(resp.StatusCode))
+		return AnalysisResult{}, upstreamStatusError(resp.StatusCode)
 	}
 
-	// Parse the HTML
-	doc, err := html.Parse(resp.Body)
+	page, err := newPageBody(resp, a.opts.MaxDocumentSize, a.opts.MaxDecompressedSize)
 	if err != nil {
 		return AnalysisResult{}, err
 	}
 
-	baseURL, err := url.Parse(urlStr)
+	body := bufio.NewReaderSize(page, prescanLen)
+	contentType, err := detectContentType(resp.Header, body)
 	if err != nil {
 		return AnalysisResult{}, err
 	}
 
+	head, _ := body.Peek(prescanLen)
+	enc, encodingInfo := detectEncoding(resp.Header, head)
+
+	// Parse the HTML
+	doc, err := html.Parse(decodeBody(body, enc))
+	if err != nil {
+		return AnalysisResult{}, classifyFetchError(err)
+	}
+
+	// links, images, scripts and stylesheets share the link checks of the analysis
+	ctx, budget := a.withLinkCheckBudget(ctx)
+
 	var wg sync.WaitGroup
-	result := AnalysisResult{}
+	result := AnalysisResult{ContentType: contentType, Encoding: encodingInfo, Size: page.size()}
 	mu := sync.Mutex{}
 
 	// Goroutine for HTML version
@@ -54,8 +332,10 @@ func AnalyzeURL(urlStr string) (AnalysisResult, error) {
 	go func() {
 		defer wg.Done()
 		htmlVersion := getHTMLVersion(doc)
+		doctype := getDoctype(doc)
 		mu.Lock()
 		result.HTMLVersion = htmlVersion
+		result.Doctype = doctype
 		mu.Unlock()
 	}()
 
@@ -69,6 +349,99 @@ func AnalyzeURL(urlStr string) (AnalysisResult, error) {
 		mu.Unlock()
 	}()
 
+	// Goroutine for SEO metadata
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		seo := getSEOAudit(doc, baseURL, resp.Header)
+		mu.Lock()
+		result.SEO = seo
+		mu.Unlock()
+	}()
+
+	// Goroutine for security headers
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		securityHeaders := getSecurityHeaders(resp.Header, baseURL)
+		mu.Lock()
+		result.SecurityHeaders = securityHeaders
+		mu.Unlock()
+	}()
+
+	// Goroutine for Open Graph and Twitter Card metadata
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		social := getSocialMeta(doc, baseURL)
+		a.checkSocialImages(ctx, social.Images)
+		mu.Lock()
+		result.Social = social
+		mu.Unlock()
+	}()
+
+	// Goroutine for structured data
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		structuredData := getStructuredData(doc, baseURL)
+		mu.Lock()
+		result.StructuredData = structuredData
+		mu.Unlock()
+	}()
+
+	// Goroutine for accessibility
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		accessibility := getAccessibilityAudit(doc)
+		mu.Lock()
+		result.Accessibility = accessibility
+		mu.Unlock()
+	}()
+
+	// Goroutine for images
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		images := getImages(doc, baseURL)
+		a.checkImages(ctx, images)
+		mu.Lock()
+		result.Images = images
+		mu.Unlock()
+	}()
+
+	// Goroutine for scripts and stylesheets
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		resources := getResources(doc, baseURL)
+		a.checkResources(ctx, &resources)
+		mu.Lock()
+		result.Resources = resources
+		mu.Unlock()
+	}()
+
+	// Goroutine for third parties
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		thirdParties := getThirdParties(doc, baseURL, a.opts.InternalHosts, a.trackers)
+		mu.Lock()
+		result.ThirdParties = thirdParties
+		mu.Unlock()
+	}()
+
+	// Goroutine for mixed content
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		mixedContent := getMixedContent(doc, baseURL)
+		mu.Lock()
+		result.MixedContent = mixedContent
+		mu.Unlock()
+	}()
+
 	// Goroutine for headings
 	wg.Add(1)
 	go func() {
@@ -79,23 +452,25 @@ func AnalyzeURL(urlStr string) (AnalysisResult, error) {
 		mu.Unlock()
 	}()
 
-	// Goroutine for internal links
+	// Goroutine for heading outline
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		internalLinks := getNumInternalLinks(doc, baseURL)
+		outline := getOutline(doc)
 		mu.Lock()
-		result.NumInternalLinks = internalLinks
+		result.Outline = outline
 		mu.Unlock()
 	}()
 
-	// Goroutine for external links
+	// Goroutine for internal and external links
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		externalLinks := getNumExternalLinks(doc, baseURL)
+		scopes := getLinkScopes(doc, baseURL, a.opts.InternalHosts)
 		mu.Lock()
-		result.NumExternalLinks = externalLinks
+		result.LinkScopes = scopes
+		result.NumInternalLinks = scopes.SameHost + scopes.SameSite
+		result.NumExternalLinks = scopes.External
 		mu.Unlock()
 	}()
 
@@ -103,24 +478,37 @@ func AnalyzeURL(urlStr string) (AnalysisResult, error) {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		inaccessibleLinks := getNumInaccessibleLinks(doc)
+		inaccessibleLinks := a.getNumInaccessibleLinks(ctx, doc)
 		mu.Lock()
 		result.NumInaccessibleLinks = inaccessibleLinks
 		mu.Unlock()
 	}()
 
-	// Goroutine for login form
+	// Goroutine for form inventory
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		containsLoginForm := isContainLoginForm(doc)
+		forms := getForms(doc, baseURL)
 		mu.Lock()
-		result.IsContainLoginForm = containsLoginForm
+		result.Forms = forms
+		mu.Unlock()
+	}()
+
+	// Goroutine for login and other authentication forms
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		authForms := getAuthForms(doc, baseURL)
+		mu.Lock()
+		result.AuthForms = authForms
+		result.IsContainLoginForm = hasLoginForm(authForms)
 		mu.Unlock()
 	}()
 
 	wg.Wait()
 
+	result.NumUncheckedLinks = budget.numSkipped()
+
 	return result, nil
 }
 
@@ -143,82 +531,27 @@ func getHeadings(doc *html.Node) map[string]int {
 	return headings
 }
 
-// isAccessible checks if a link is accessible
-func isAccessible(link string) bool {
-	resp, err := http.Head(link)
-	if err != nil || resp.StatusCode != http.StatusOK {
-		return false
-	}
-	return true
-}
-
-// getNumInaccessibleLinks traverses the HTML document and returns the count of inaccessible links
-func getNumInaccessibleLinks(doc *html.Node) int {
-	var links []string
-	var traverse func(*html.Node)
-	traverse = func(n *html.Node) {
-		if n.Type == html.ElementNode && n.Data == "a" {
-			for _, attr := range n.Attr {
-				if attr.Key == "href" {
-					links = append(links, attr.Val)
-					break
-				}
-			}
-		}
-		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			traverse(c)
-		}
-	}
-	traverse(doc)
-
-	inaccessibleCount := 0
-	for _, link := range links {
-		if !isAccessible(link) {
-			inaccessibleCount++
-		}
+// withTimeout derives a context bounded by timeout, or leaves ctx unbounded when timeout is zero
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
 	}
-	return inaccessibleCount
+	return context.WithTimeout(ctx, timeout)
 }
 
-// isExternalLink checks if a link is external
-func isExternalLink(link string, baseURL *url.URL) bool {
-	u, err := url.Parse(link)
+// isAccessible checks if a link is accessible. It reports checked false when the link
+// was not checked because the analysis ended first.
+func (a *Analyzer) isAccessible(ctx context.Context, link string) (accessible, checked bool) {
+	resp, err := a.sendCheck(ctx, http.MethodHead, link)
 	if err != nil {
-		return false
+		return false, !errors.Is(err, errUnchecked)
 	}
-	return u.Host != "" && u.Host != baseURL.Host
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusOK, true
 }
 
-// getNumExternalLinks returns the number of external links in the HTML document
-func getNumExternalLinks(doc *html.Node, baseURL *url.URL) int {
-	var links []string
-	var traverse func(*html.Node)
-	traverse = func(n *html.Node) {
-		if n.Type == html.ElementNode && n.Data == "a" {
-			for _, attr := range n.Attr {
-				if attr.Key == "href" {
-					links = append(links, attr.Val)
-					break
-				}
-			}
-		}
-		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			traverse(c)
-		}
-	}
-	traverse(doc)
-
-	externalCount := 0
-	for _, link := range links {
-		if isExternalLink(link, baseURL) {
-			externalCount++
-		}
-	}
-	return externalCount
-}
-
-// getNumInternalLinks returns the number of internal links in the HTML document
-func getNumInternalLinks(doc *html.Node, baseURL *url.URL) int {
+// getNumInaccessibleLinks traverses the HTML document and returns the count of inaccessible links
+func (a *Analyzer) getNumInaccessibleLinks(ctx context.Context, doc *html.Node) int {
 	var links []string
 	var traverse func(*html.Node)
 	traverse = func(n *html.Node) {
@@ -236,38 +569,31 @@ func getNumInternalLinks(doc *html.Node, baseURL *url.URL) int {
 	}
 	traverse(doc)
 
-	internalCount := 0
+	// check links as long as the link check budget lasts
+	budget := a.budgetFrom(ctx)
+	var wg sync.WaitGroup
+	var inaccessibleCount atomic.Int64
 	for _, link := range links {
-		if !isExternalLink(link, baseURL) {
-			internalCount++
-		}
-	}
-	return internalCount
-}
-
-// isContainLoginForm checks if the document contains a login form
-func isContainLoginForm(doc *html.Node) bool {
-	var traverse func(*html.Node) bool
-	traverse = func(n *html.Node) bool {
-		if n.Type == html.ElementNode && n.Data == "form" {
-			for c := n.FirstChild; c != nil; c = c.NextSibling {
-				if c.Type == html.ElementNode && c.Data == "input" {
-					for _, attr := range c.Attr {
-						if attr.Key == "type" && attr.Val == "password" {
-							return true
-						}
-					}
-				}
-			}
+		if !budget.take() {
+			budget.skip()
+			continue
 		}
-		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			if traverse(c) {
-				return true
+		wg.Add(1)
+		budget.acquire()
+		go func(link string) {
+			defer wg.Done()
+			defer budget.release()
+			accessible, checked := a.isAccessible(ctx, link)
+			switch {
+			case !checked:
+				budget.skip()
+			case !accessible:
+				inaccessibleCount.Add(1)
 			}
-		}
-		return false
+		}(link)
 	}
-	return traverse(doc)
+	wg.Wait()
+	return int(inaccessibleCount.Load())
 }
 
 // getPageTitle extracts the global title of an HTML document from the provided html.Node
@@ -288,45 +614,3 @@ func getPageTitle(doc *html.Node) string {
 	traverse(doc)
 	return title
 }
-
-// getHTMLVersion determines the version of the HTML document by inspecting the doctype
-// doctypes are taken from https://www.w3.org/QA/2002/04/valid-dtd-list.html
-func getHTMLVersion(doc *html.Node) string {
-	if doc.Type == html.DoctypeNode {
-		doctype := strings.ToLower(doc.Data)
-		if doctype == "html" && (doc.Attr == nil || len(doc.Attr) == 0) {
-			return "HTML5"
-		}
-
-		if len(doc.Attr) > 0 {
-			doctype = strings.ToLower(doc.Attr[0].Val)
-		}
-
-		switch {
-		case strings.Contains(doctype, "4.01 transitional"):
-			return "HTML 4.01 Transitional"
-		case strings.Contains(doctype, "4.01 frameset"):
-			return "HTML 4.01 Frameset"
-		case strings.Contains(doctype, "4.01"):
-			return "HTML 4.01 Strict"
-		case strings.Contains(doctype, "xhtml 1.0 strict"):
-			return "XHTML 1.0 Strict"
-		case strings.Contains(doctype, "xhtml 1.0 transitional"):
-			return "XHTML 1.0 Transitional"
-		case strings.Contains(doctype, "xhtml 1.0 frameset"):
-			return "XHTML 1.0 Frameset"
-		case strings.Contains(doctype, "xhtml basic 1.1"):
-			return "XHTML Basic 1.1"
-		case strings.Contains(doctype, "xhtml 1.1"):
-			return "XHTML 1.1"
-		default:
-			return "Unknown"
-		}
-	}
-	for c := doc.FirstChild; c != nil; c = c.NextSibling {
-		if version := getHTMLVersion(c); version != "" {
-			return version
-		}
-	}
-	return "Unknown"
-}
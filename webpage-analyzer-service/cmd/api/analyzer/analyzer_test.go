@@ -1,11 +1,14 @@
 package analyzer
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
-	"net/url"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"golang.org/x/net/html"
 )
@@ -28,57 +31,6 @@ func equalMaps(a, b map[string]int) bool {
 	return true
 }
 
-// Table-driven tests for isExternalLink
-func TestIsExternalLink(t *testing.T) {
-	baseURL, _ := url.Parse("https://www.example.com")
-
-	tests := []struct {
-		name     string
-		link     string
-		expected bool
-	}{
-		{
-			name:     "RelativeLink",
-			link:     "/internal-link",
-			expected: false,
-		},
-		{
-			name:     "SameHostAbsoluteLink",
-			link:     "https://www.example.com/internal-link",
-			expected: false,
-		},
-		{
-			name:     "DifferentHostLink",
-			link:     "https://www.google.com",
-			expected: true,
-		},
-		{
-			name:     "DifferentHostWithPathLink",
-			link:     "https://www.example.org/path",
-			expected: true,
-		},
-		{
-			name:     "EmptyLink",
-			link:     "",
-			expected: false,
-		},
-		{
-			name:     "InvalidLink",
-			link:     ":invalid-link",
-			expected: false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := isExternalLink(tt.link, baseURL)
-			if got != tt.expected {
-				t.Errorf("isExternalLink(%q, %v) = %v, want %v", tt.link, baseURL, got, tt.expected)
-			}
-		})
-	}
-}
-
 // Table-driven tests for isAccessible
 func TestIsAccessible(t *testing.T) {
 	tests := []struct {
@@ -109,7 +61,7 @@ func TestIsAccessible(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := isAccessible(tt.link)
+			got, _ := New(DefaultOptions()).isAccessible(context.Background(), tt.link)
 			if got != tt.expected {
 				t.Errorf("isAccessible(%q) = %v, want %v", tt.link, got, tt.expected)
 			}
@@ -244,7 +196,7 @@ func TestGetNumInaccessibleLinks(t *testing.T) {
 			if err != nil {
 				t.Fatalf("Failed to parse HTML: %v", err)
 			}
-			got := getNumInaccessibleLinks(doc)
+			got := New(DefaultOptions()).getNumInaccessibleLinks(context.Background(), doc)
 			if got != tt.expected {
 				t.Errorf("getInaccessibleLinks() = %v, want %v", got, tt.expected)
 			}
@@ -252,123 +204,8 @@ func TestGetNumInaccessibleLinks(t *testing.T) {
 	}
 }
 
-// Table-driven tests for getNumExternalLinks
-func TestGetNumExternalLinks(t *testing.T) {
-	baseURL, _ := url.Parse("https://www.example.com")
-
-	tests := []struct {
-		name     string
-		html     string
-		expected int
-	}{
-		{
-			name:     "NoLinks",
-			html:     `<html><head><title>Test</title></head><body></body></html>`,
-			expected: 0,
-		},
-		{
-			name: "NoExternalLinks",
-			html: `<!DOCTYPE html><html><head><title>Test</title></head><body>
-				<a href="/internal-link">Internal Link</a>
-				<a href="/another-internal-link">Another Internal Link</a>
-			</body></html>`,
-			expected: 0,
-		},
-		{
-			name: "SomeExternalLinks",
-			html: `<!DOCTYPE html><html><head><title>Test</title></head><body>
-				<a href="https://www.google.com">Google</a>
-				<a href="/internal-link">Internal Link</a>
-				<a href="https://www.example.org">Example</a>
-				<a href="https://www.example.com/InternalLink">Internal Link Example</a>
-			</body></html>`,
-			expected: 2,
-		},
-		{
-			name: "AllExternalLinks",
-			html: `<html><head><title>Test</title></head><body>
-				<h1>Example Domain</h1>
-				<p>This domain is for use in illustrative examples in documents. You may use this
-				domain in literature without prior coordination or asking for permission.</p>
-				<p><a href="https://www.iana.org/domains/example">More information...</a></p>
-				<a href="https://www.google.com">Google</a>
-				<a href="https://www.example.org">Example</a>
-			</body></html>`,
-			expected: 3,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			doc, err := parseHTML(tt.html)
-			if err != nil {
-				t.Fatalf("Failed to parse HTML: %v", err)
-			}
-			got := getNumExternalLinks(doc, baseURL)
-			if got != tt.expected {
-				t.Errorf("getExternalLinks() = %v, want %v", got, tt.expected)
-			}
-		})
-	}
-}
-
-// Table-driven tests for getNumInternalLinks
-func TestGetNumInternalLinks(t *testing.T) {
-	baseURL, _ := url.Parse("https://www.example.com")
-
-	tests := []struct {
-		name     string
-		html     string
-		expected int
-	}{
-		{
-			name:     "NoLinks",
-			html:     `<!DOCTYPE html><html><head><title>Test</title></head><body></body></html>`,
-			expected: 0,
-		},
-		{
-			name: "NoInternalLinks",
-			html: `<html><head><title>Test</title></head><body>
-				<a href="https://www.google.com">Google</a>
-				<a href="https://www.example.org">Example</a>
-			</body></html>`,
-			expected: 0,
-		},
-		{
-			name: "SomeInternalLinks",
-			html: `<!DOCTYPE html><html><head><title>Test</title></head><body>
-				<a href="/internal-link">Internal Link</a>
-				<a href="https://www.example.com/another-internal-link">Another Internal Link</a>
-				<a href="https://www.google.com">Google</a>
-			</body></html>`,
-			expected: 2,
-		},
-		{
-			name: "AllInternalLinks",
-			html: `<!DOCTYPE html><html><head><title>Test</title></head><body>
-				<a href="/internal-link">Internal Link</a>
-				<a href="https://www.example.com/another-internal-link">Another Internal Link</a>
-			</body></html>`,
-			expected: 2,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			doc, err := parseHTML(tt.html)
-			if err != nil {
-				t.Fatalf("Failed to parse HTML: %v", err)
-			}
-			got := getNumInternalLinks(doc, baseURL)
-			if got != tt.expected {
-				t.Errorf("getInternalLinks() = %v, want %v", got, tt.expected)
-			}
-		})
-	}
-}
-
-// Table-driven tests for isContainLoginForm
-func TestIsContainLoginForm(t *testing.T) {
+// Table-driven tests for hasLoginForm
+func TestHasLoginForm(t *testing.T) {
 	tests := []struct {
 		name     string
 		html     string
@@ -400,6 +237,16 @@ func TestIsContainLoginForm(t *testing.T) {
 			</body></html>`,
 			expected: true,
 		},
+		{
+			name: "LonePasswordField",
+			html: `<html><head><title>Test</title></head><body>
+				<form>
+					<input type="password" name="pin">
+					<input type="submit" value="Submit">
+				</form>
+			</body></html>`,
+			expected: false,
+		},
 		{
 			name: "NestedFormWithPassword",
 			html: `<html><head><title>Test</title></head><body>
@@ -428,6 +275,29 @@ func TestIsContainLoginForm(t *testing.T) {
 			</body></html>`,
 			expected: true,
 		},
+		{
+			name: "PasswordWrappedInDivs",
+			html: `<html><head><title>Test</title></head><body>
+				<form action="/session" method="post">
+					<div class="field"><label>Email <input type="email" name="email"></label></div>
+					<div class="field"><input type="password" name="password"></div>
+					<button type="submit">Sign in</button>
+				</form>
+			</body></html>`,
+			expected: true,
+		},
+		{
+			name: "SignupFormOnly",
+			html: `<html><head><title>Test</title></head><body>
+				<form action="/register" method="post">
+					<input type="email" name="email">
+					<input type="password" name="password" autocomplete="new-password">
+					<input type="password" name="confirm" autocomplete="new-password">
+					<button>Create account</button>
+				</form>
+			</body></html>`,
+			expected: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -436,9 +306,9 @@ func TestIsContainLoginForm(t *testing.T) {
 			if err != nil {
 				t.Fatalf("Failed to parse HTML: %v", err)
 			}
-			got := isContainLoginForm(doc)
+			got := hasLoginForm(getAuthForms(doc, nil))
 			if got != tt.expected {
-				t.Errorf("containsLoginForm() = %v, want %v", got, tt.expected)
+				t.Errorf("hasLoginForm() = %v, want %v", got, tt.expected)
 			}
 		})
 	}
@@ -528,6 +398,51 @@ func TestGetHTMLVersion(t *testing.T) {
 		{
 			name:     "No DOCTYPE",
 			html:     `<html><head><title>Test</title></head><body></body></html>`,
+			expected: "No DOCTYPE",
+		},
+		{
+			name:     "HTML5 legacy-compat",
+			html:     `<!DOCTYPE html SYSTEM "about:legacy-compat"><html></html>`,
+			expected: "HTML5 (legacy-compat)",
+		},
+		{
+			name:     "HTML 4.01 Strict",
+			html:     `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01//EN" "http://www.w3.org/TR/html4/strict.dtd"><html></html>`,
+			expected: "HTML 4.01 Strict",
+		},
+		{
+			name:     "HTML 3.2",
+			html:     `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 3.2 Final//EN"><html></html>`,
+			expected: "HTML 3.2",
+		},
+		{
+			name:     "HTML 2.0",
+			html:     `<!DOCTYPE html PUBLIC "-//IETF//DTD HTML 2.0//EN"><html></html>`,
+			expected: "HTML 2.0",
+		},
+		{
+			name:     "XHTML Mobile Profile 1.2",
+			html:     `<!DOCTYPE html PUBLIC "-//WAPFORUM//DTD XHTML Mobile 1.2//EN" "http://www.openmobilealliance.org/tech/DTD/xhtml-mobile12.dtd"><html></html>`,
+			expected: "XHTML Mobile Profile 1.2",
+		},
+		{
+			name:     "XHTML+MathML+SVG",
+			html:     `<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.1 plus MathML 2.0 plus SVG 1.1//EN" "http://www.w3.org/2002/04/xhtml-math-svg/xhtml-math-svg.dtd"><html></html>`,
+			expected: "XHTML 1.1 plus MathML 2.0 plus SVG 1.1",
+		},
+		{
+			name:     "SVG 1.1",
+			html:     `<!DOCTYPE svg PUBLIC "-//W3C//DTD SVG 1.1//EN" "http://www.w3.org/Graphics/SVG/1.1/DTD/svg11.dtd"><svg></svg>`,
+			expected: "SVG 1.1 Full",
+		},
+		{
+			name:     "MathML 1.01",
+			html:     `<!DOCTYPE math SYSTEM "http://www.w3.org/Math/DTD/mathml1/mathml.dtd"><math></math>`,
+			expected: "MathML 1.01",
+		},
+		{
+			name:     "Unknown public identifier",
+			html:     `<!DOCTYPE html PUBLIC "-//Example//DTD Custom HTML//EN"><html></html>`,
 			expected: "Unknown",
 		},
 	}
@@ -659,3 +574,130 @@ func TestAnalyzeURL(t *testing.T) {
 		})
 	}
 }
+
+// Table-driven tests for the link check limit
+func TestAnalyzeURLLinkCheckLimit(t *testing.T) {
+	tests := []struct {
+		name                 string
+		limit                int
+		expectedInaccessible int
+		expectedUnchecked    int
+	}{
+		{
+			name:                 "NoLimit",
+			limit:                0,
+			expectedInaccessible: 2,
+			expectedUnchecked:    0,
+		},
+		{
+			name:                 "LimitBelowLinkCount",
+			limit:                2,
+			expectedInaccessible: 1,
+			expectedUnchecked:    1,
+		},
+		{
+			name:                 "LimitAboveLinkCount",
+			limit:                10,
+			expectedInaccessible: 2,
+			expectedUnchecked:    0,
+		},
+	}
+
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`<!DOCTYPE html><html><head><title>Links</title></head><body>
+				<a href="` + ts.URL + `/ok">OK</a>
+				<a href="` + ts.URL + `/missing">Missing</a>
+				<a href="` + ts.URL + `/gone">Gone</a>
+			</body></html>`))
+		case "/ok":
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := WithLinkCheckLimit(context.Background(), tt.limit)
+			got, err := New(DefaultOptions()).AnalyzeURL(ctx, ts.URL)
+			if err != nil {
+				t.Fatalf("AnalyzeURL() error = %v", err)
+			}
+			if got.NumInaccessibleLinks != tt.expectedInaccessible {
+				t.Errorf("InaccessibleLinks = %v, want %v", got.NumInaccessibleLinks, tt.expectedInaccessible)
+			}
+			if got.NumUncheckedLinks != tt.expectedUnchecked {
+				t.Errorf("UncheckedLinks = %v, want %v", got.NumUncheckedLinks, tt.expectedUnchecked)
+			}
+		})
+	}
+}
+
+func TestAnalyzeURLSharedLinkCheckBudget(t *testing.T) {
+	var checks atomic.Int64
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			w.Write([]byte(`<!DOCTYPE html><html><head><title>Budget</title>
+				<meta property="og:image" content="` + ts.URL + `/social.png">
+				<script src="` + ts.URL + `/app.js"></script>
+			</head><body>
+				<a href="` + ts.URL + `/a">A</a><a href="` + ts.URL + `/b">B</a>
+				<img src="` + ts.URL + `/a.png" alt=""><img src="` + ts.URL + `/b.png" alt="">
+			</body></html>`))
+			return
+		}
+		checks.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	// two links, two images, a script and a social image draw from a budget of three checks
+	ctx := WithLinkCheckLimit(context.Background(), 3)
+	got, err := New(DefaultOptions()).AnalyzeURL(ctx, ts.URL)
+	if err != nil {
+		t.Fatalf("AnalyzeURL() error = %v", err)
+	}
+	if n := checks.Load(); n != 3 {
+		t.Errorf("checks sent = %v, want 3", n)
+	}
+	if got.NumUncheckedLinks != 3 {
+		t.Errorf("UncheckedLinks = %v, want 3", got.NumUncheckedLinks)
+	}
+}
+
+func TestAnalyzeURLRateLimitedLinkChecks(t *testing.T) {
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		var links strings.Builder
+		for i := 0; i < 10; i++ {
+			fmt.Fprintf(&links, `<a href="%s/page/%d">Page</a>`, ts.URL, i)
+		}
+		w.Write([]byte(`<!DOCTYPE html><html><head><title>Links</title></head><body>` + links.String() + `</body></html>`))
+	}))
+	defer ts.Close()
+
+	// the links queue for the host's rate limit far longer than a single check may take
+	opts := DefaultOptions()
+	opts.HostRate = 20
+	opts.HostBurst = 1
+	opts.LinkCheckTimeout = 100 * time.Millisecond
+	got, err := New(opts).AnalyzeURL(context.Background(), ts.URL)
+	if err != nil {
+		t.Fatalf("AnalyzeURL() error = %v", err)
+	}
+	if got.NumInaccessibleLinks != 0 {
+		t.Errorf("InaccessibleLinks = %v, want 0", got.NumInaccessibleLinks)
+	}
+	if got.NumUncheckedLinks != 0 {
+		t.Errorf("UncheckedLinks = %v, want 0", got.NumUncheckedLinks)
+	}
+}
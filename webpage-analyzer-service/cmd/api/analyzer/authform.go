@@ -0,0 +1,314 @@
+package analyzer
+
+import (
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// Kinds of authentication forms
+const (
+	AuthFormLogin         = "login"
+	AuthFormSignup        = "signup"
+	AuthFormPasswordReset = "password_reset"
+)
+
+// AuthForm is a login, signup or password reset form found on the page
+type AuthForm struct {
+	Kind string `json:"kind"`
+	// Action is the resolved URL the form submits to, empty for formless logins
+	Action string `json:"action,omitempty"`
+	// Method is the upper case submit method, empty for formless logins
+	Method string `json:"method,omitempty"`
+	// Formless reports fields outside of any <form>, as single page apps submit them with scripts
+	Formless bool `json:"formless"`
+	// Score adds up the weights of the signals, higher is more certain
+	Score   int      `json:"score"`
+	Signals []string `json:"signals"`
+}
+
+// minAuthFormScore is the score a form needs to count as an authentication form. A password
+// field alone does not reach it, as PIN, card and other secret fields use the type as well.
+const minAuthFormScore = 5
+
+// authKeywords are the words in submit buttons and action URLs that hint at each kind of form.
+// They only match whole words, so "auth" does not match "oauth" or "author".
+var authKeywords = map[string]struct {
+	text   []string
+	action []string
+}{
+	AuthFormLogin: {
+		text:   []string{"sign in", "signin", "log in", "login", "log on", "logon"},
+		action: []string{"login", "signin", "sign-in", "sign_in", "logon", "session", "sessions", "auth"},
+	},
+	AuthFormSignup: {
+		text:   []string{"sign up", "signup", "register", "create account", "create an account", "join"},
+		action: []string{"signup", "sign-up", "sign_up", "register", "registration", "join"},
+	},
+	AuthFormPasswordReset: {
+		text:   []string{"forgot", "forgotten", "reset", "recover", "change password", "new password"},
+		action: []string{"reset", "forgot", "recover", "lost-password", "lostpassword"},
+	},
+}
+
+// usernameHints are found in the name, id or autocomplete of username and email fields
+var usernameHints = []string{"user", "email", "e-mail", "login", "account", "ident"}
+
+// authSignals are the clues an authentication form gives
+type authSignals struct {
+	passwordFields  int
+	currentPassword bool
+	newPassword     bool
+	usernameField   bool
+	text            map[string]bool
+	action          map[string]bool
+}
+
+// getAuthForms returns the login, signup and password reset forms of the document,
+// including password fields outside of any form. Actions are resolved against baseURL.
+func getAuthForms(doc *html.Node, baseURL *url.URL) []AuthForm {
+	authForms := []AuthForm{}
+
+	var formlessPasswords []*html.Node
+	var traverse func(n *html.Node, inForm bool)
+	traverse = func(n *html.Node, inForm bool) {
+		if n.Type == html.ElementNode {
+			switch {
+			case n.Data == "form":
+				if form, ok := classifyAuthForm(n, attrValue(n, "action"), baseURL); ok {
+					form.Method = formMethod(n)
+					authForms = append(authForms, form)
+				}
+				inForm = true
+			case !inForm && n.Data == "input" && strings.EqualFold(attrValue(n, "type"), "password"):
+				formlessPasswords = append(formlessPasswords, n)
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			traverse(c, inForm)
+		}
+	}
+	traverse(doc, false)
+
+	// a formless login is the smallest container around the password field that also holds a button
+	seen := make(map[*html.Node]bool)
+	for _, password := range formlessPasswords {
+		container := password.Parent
+		for container != nil && container.Data != "body" && !containsButton(container) {
+			container = container.Parent
+		}
+		if container == nil || container.Data == "body" {
+			container = password.Parent
+		}
+		if seen[container] {
+			continue
+		}
+		seen[container] = true
+		if form, ok := classifyAuthForm(container, "", nil); ok {
+			form.Formless = true
+			authForms = append(authForms, form)
+		}
+	}
+
+	return authForms
+}
+
+// hasLoginForm reports whether any of forms is a login form
+func hasLoginForm(forms []AuthForm) bool {
+	for _, form := range forms {
+		if form.Kind == AuthFormLogin {
+			return true
+		}
+	}
+	return false
+}
+
+// classifyAuthForm scores the fields, buttons and action of the form or container n
+// and reports whether they make up an authentication form, scoring at least minAuthFormScore
+func classifyAuthForm(n *html.Node, action string, baseURL *url.URL) (AuthForm, bool) {
+	s := collectAuthSignals(n, action)
+	resetSignal := s.text[AuthFormPasswordReset] || s.action[AuthFormPasswordReset]
+
+	// without a password field only a reset request form asking for the account counts
+	if s.passwordFields == 0 && !(resetSignal && s.usernameField) {
+		return AuthForm{}, false
+	}
+
+	form := AuthForm{Signals: []string{}}
+	add := func(signal string, weight int) {
+		form.Signals = append(form.Signals, signal)
+		form.Score += weight
+	}
+	if s.passwordFields > 0 {
+		add("password_field", 3)
+	}
+	if s.passwordFields > 1 {
+		add("confirm_password_field", 2)
+	}
+	if s.currentPassword {
+		add("autocomplete_current_password", 3)
+	}
+	if s.newPassword {
+		add("autocomplete_new_password", 2)
+	}
+	if s.usernameField {
+		add("username_field", 2)
+	}
+	for _, kind := range []string{AuthFormLogin, AuthFormSignup, AuthFormPasswordReset} {
+		if s.text[kind] {
+			add(kind+"_text", 2)
+		}
+		if s.action[kind] {
+			add(kind+"_action", 1)
+		}
+	}
+
+	if form.Score < minAuthFormScore {
+		return AuthForm{}, false
+	}
+
+	switch {
+	case resetSignal && !s.currentPassword:
+		form.Kind = AuthFormPasswordReset
+	case !s.currentPassword && (s.text[AuthFormSignup] || s.action[AuthFormSignup] || s.passwordFields > 1 || s.newPassword):
+		form.Kind = AuthFormSignup
+	default:
+		form.Kind = AuthFormLogin
+	}
+
+	if baseURL != nil {
+		form.Action = resolveURL(baseURL, action)
+	}
+	return form, true
+}
+
+// collectAuthSignals gathers the clues in the descendants of n and in the form action
+func collectAuthSignals(n *html.Node, action string) authSignals {
+	s := authSignals{text: make(map[string]bool), action: make(map[string]bool)}
+
+	var traverse func(*html.Node)
+	traverse = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.Data {
+			case "input":
+				inputType := strings.ToLower(attrValue(n, "type"))
+				autocomplete := strings.ToLower(attrValue(n, "autocomplete"))
+				switch {
+				case inputType == "password":
+					s.passwordFields++
+					s.currentPassword = s.currentPassword || strings.Contains(autocomplete, "current-password")
+					s.newPassword = s.newPassword || strings.Contains(autocomplete, "new-password")
+				case inputType == "email":
+					s.usernameField = true
+				case inputType == "" || inputType == "text" || inputType == "tel":
+					hints := strings.ToLower(attrValue(n, "name") + " " + attrValue(n, "id") + " " + autocomplete)
+					s.usernameField = s.usernameField || containsAny(hints, usernameHints)
+				case inputType == "submit" || inputType == "button":
+					matchAuthText(s.text, attrValue(n, "value"))
+				}
+			case "button":
+				matchAuthText(s.text, textContent(n))
+				return
+			case "a":
+				// links such as "Forgot password?" sit next to login forms and say nothing about the form itself
+				return
+			}
+			if strings.EqualFold(attrValue(n, "role"), "button") {
+				matchAuthText(s.text, textContent(n))
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			traverse(c)
+		}
+	}
+	traverse(n)
+
+	if action != "" {
+		path := strings.ToLower(action)
+		if u, err := url.Parse(action); err == nil {
+			path = strings.ToLower(u.Path)
+		}
+		for kind, keywords := range authKeywords {
+			if containsWord(path, keywords.action) {
+				s.action[kind] = true
+			}
+		}
+	}
+
+	return s
+}
+
+// matchAuthText records the kinds of form the button text hints at
+func matchAuthText(matches map[string]bool, text string) {
+	text = strings.ToLower(strings.Join(strings.Fields(text), " "))
+	for kind, keywords := range authKeywords {
+		if containsWord(text, keywords.text) {
+			matches[kind] = true
+		}
+	}
+}
+
+// containsButton reports whether n has a descendant that submits
+func containsButton(n *html.Node) bool {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		inputType := strings.ToLower(attrValue(c, "type"))
+		if c.Data == "button" || (c.Data == "input" && (inputType == "submit" || inputType == "button")) ||
+			strings.EqualFold(attrValue(c, "role"), "button") {
+			return true
+		}
+		if containsButton(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// formMethod returns the upper case submit method of form, GET when it is missing
+func formMethod(form *html.Node) string {
+	method := strings.ToUpper(strings.TrimSpace(attrValue(form, "method")))
+	if method == "" {
+		return "GET"
+	}
+	return method
+}
+
+// containsWord reports whether s contains one of words with no letter or digit right
+// before or after it
+func containsWord(s string, words []string) bool {
+	for _, word := range words {
+		for i := 0; i+len(word) <= len(s); {
+			j := strings.Index(s[i:], word)
+			if j < 0 {
+				break
+			}
+			start, end := i+j, i+j+len(word)
+			before, _ := utf8.DecodeLastRuneInString(s[:start])
+			after, _ := utf8.DecodeRuneInString(s[end:])
+			if !isWordRune(before) && !isWordRune(after) {
+				return true
+			}
+			i = start + 1
+		}
+	}
+	return false
+}
+
+// isWordRune reports whether r is part of a word, utf8.RuneError standing for the ends of the string
+func isWordRune(r rune) bool {
+	return r != utf8.RuneError && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+func containsAny(s string, substrs []string) bool {
+	for _, substr := range substrs {
+		if strings.Contains(s, substr) {
+			return true
+		}
+	}
+	return false
+}
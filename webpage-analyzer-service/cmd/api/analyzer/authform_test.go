@@ -0,0 +1,193 @@
+package analyzer
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// Table-driven tests for getAuthForms
+func TestGetAuthForms(t *testing.T) {
+	baseURL, _ := url.Parse("https://www.example.com/account/")
+
+	tests := []struct {
+		name     string
+		html     string
+		expected []AuthForm
+	}{
+		{
+			name:     "NoForms",
+			html:     `<html><body><p>Hello</p></body></html>`,
+			expected: []AuthForm{},
+		},
+		{
+			name: "Login",
+			html: `<html><body>
+				<form action="/login" method="post">
+					<div><input type="text" name="username" autocomplete="username"></div>
+					<div><input type="password" name="password" autocomplete="current-password"></div>
+					<div><button type="submit"><span>Sign in</span></button></div>
+					<a href="/forgot">Forgot password?</a>
+				</form>
+			</body></html>`,
+			expected: []AuthForm{{
+				Kind:    AuthFormLogin,
+				Action:  "https://www.example.com/login",
+				Method:  "POST",
+				Score:   11,
+				Signals: []string{"password_field", "autocomplete_current_password", "username_field", "login_text", "login_action"},
+			}},
+		},
+		{
+			name: "LoginWithoutAction",
+			html: `<html><body>
+				<form><input type="text" name="user"><input type="password" name="pass"><input type="submit" value="OK"></form>
+			</body></html>`,
+			expected: []AuthForm{{
+				Kind:    AuthFormLogin,
+				Action:  "https://www.example.com/account/",
+				Method:  "GET",
+				Score:   5,
+				Signals: []string{"password_field", "username_field"},
+			}},
+		},
+		{
+			name: "LonePasswordFieldIsNotAuth",
+			html: `<html><body>
+				<form action="/checkout"><input type="password" name="pin"><input type="submit" value="OK"></form>
+			</body></html>`,
+			expected: []AuthForm{},
+		},
+		{
+			name: "KeywordsInsideWords",
+			html: `<html><body>
+				<form action="/oauth/authorize" method="post">
+					<input type="email" name="email">
+					<input type="password" name="password" autocomplete="current-password">
+					<button>Continue</button>
+				</form>
+				<form action="/author/profile" method="post">
+					<input type="email" name="email">
+					<input type="password" name="password">
+					<button>Joined already? Log in</button>
+				</form>
+			</body></html>`,
+			expected: []AuthForm{
+				{
+					Kind:    AuthFormLogin,
+					Action:  "https://www.example.com/oauth/authorize",
+					Method:  "POST",
+					Score:   8,
+					Signals: []string{"password_field", "autocomplete_current_password", "username_field"},
+				},
+				{
+					Kind:    AuthFormLogin,
+					Action:  "https://www.example.com/author/profile",
+					Method:  "POST",
+					Score:   7,
+					Signals: []string{"password_field", "username_field", "login_text"},
+				},
+			},
+		},
+		{
+			name: "Signup",
+			html: `<html><body>
+				<form action="signup" method="POST">
+					<input type="email" name="email">
+					<input type="password" name="password">
+					<input type="password" name="password_confirmation">
+					<input type="submit" value="Create account">
+				</form>
+			</body></html>`,
+			expected: []AuthForm{{
+				Kind:    AuthFormSignup,
+				Action:  "https://www.example.com/account/signup",
+				Method:  "POST",
+				Score:   10,
+				Signals: []string{"password_field", "confirm_password_field", "username_field", "signup_text", "signup_action"},
+			}},
+		},
+		{
+			name: "PasswordResetRequest",
+			html: `<html><body>
+				<form action="/password/reset" method="post">
+					<input type="email" name="email">
+					<button>Send reset link</button>
+				</form>
+			</body></html>`,
+			expected: []AuthForm{{
+				Kind:    AuthFormPasswordReset,
+				Action:  "https://www.example.com/password/reset",
+				Method:  "POST",
+				Score:   5,
+				Signals: []string{"username_field", "password_reset_text", "password_reset_action"},
+			}},
+		},
+		{
+			name: "NewsletterIsNotAuth",
+			html: `<html><body>
+				<form action="/newsletter"><input type="email" name="email"><button>Subscribe</button></form>
+				<form action="/search"><input type="text" name="q"><button>Search</button></form>
+			</body></html>`,
+			expected: []AuthForm{},
+		},
+		{
+			name: "FormlessSPALogin",
+			html: `<html><body>
+				<div id="app">
+					<div class="login">
+						<input type="email" placeholder="Email">
+						<input type="password" placeholder="Password">
+						<div role="button">Log in</div>
+					</div>
+				</div>
+			</body></html>`,
+			expected: []AuthForm{{
+				Kind:     AuthFormLogin,
+				Formless: true,
+				Score:    7,
+				Signals:  []string{"password_field", "username_field", "login_text"},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parseHTML(tt.html)
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+			if got := getAuthForms(doc, baseURL); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("getAuthForms() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+// Table-driven tests for containsWord
+func TestContainsWord(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        string
+		words    []string
+		expected bool
+	}{
+		{name: "Whole", s: "join", words: []string{"join"}, expected: true},
+		{name: "BetweenSeparators", s: "/auth/login", words: []string{"auth"}, expected: true},
+		{name: "Phrase", s: "please log in now", words: []string{"log in"}, expected: true},
+		{name: "Suffix", s: "joined", words: []string{"join"}, expected: false},
+		{name: "Prefix", s: "/oauth", words: []string{"auth"}, expected: false},
+		{name: "Infix", s: "/author", words: []string{"auth"}, expected: false},
+		{name: "LaterOccurrence", s: "author auth", words: []string{"auth"}, expected: true},
+		{name: "NonASCIINeighbour", s: "éjoin", words: []string{"join"}, expected: false},
+		{name: "Empty", s: "", words: []string{"join"}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := containsWord(tt.s, tt.words); got != tt.expected {
+				t.Errorf("containsWord(%q, %q) = %v, want %v", tt.s, tt.words, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package analyzer
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// acceptEncoding lists the content codings the analyzer decompresses itself,
+// so the transferred and the decompressed size can both be measured and limited
+const acceptEncoding = "gzip, deflate"
+
+// SizeInfo describes how large the page is and how it was transferred
+type SizeInfo struct {
+	// Bytes is the size of the document after decompression
+	Bytes int64 `json:"bytes"`
+	// TransferBytes is the size of the body as it was received
+	TransferBytes int64 `json:"transferBytes"`
+	// Compression is the content coding the body was sent with: gzip, deflate or identity
+	Compression string `json:"compression"`
+}
+
+// limitedReader counts the bytes read from r and fails with err once more than limit were read
+type limitedReader struct {
+	r     io.Reader
+	n     int64
+	limit int64
+	err   error
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n > l.limit {
+		return 0, l.err
+	}
+	// reading one byte past the limit tells a body of exactly limit bytes from a larger one
+	if remaining := l.limit + 1 - l.n; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	n, err := l.r.Read(p)
+	l.n += int64(n)
+	if l.n > l.limit {
+		return n, l.err
+	}
+	return n, err
+}
+
+// pageBody reads the decompressed page body while enforcing the size limits
+type pageBody struct {
+	io.Reader
+	transfer    *limitedReader
+	decoded     *limitedReader
+	compression string
+}
+
+// newPageBody wraps the body of resp, failing with a too_large error as soon as the body
+// exceeds maxTransferSize bytes or decompresses to more than maxDecompressedSize bytes
+func newPageBody(resp *http.Response, maxTransferSize, maxDecompressedSize int64) (*pageBody, error) {
+	if resp.ContentLength > maxTransferSize {
+		return nil, tooLargeError("the page", maxTransferSize)
+	}
+
+	b := &pageBody{
+		transfer: &limitedReader{r: resp.Body, limit: maxTransferSize, err: tooLargeError("the page", maxTransferSize)},
+	}
+
+	var decompressed io.Reader
+	switch coding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))); coding {
+	case "", "identity":
+		b.compression = "identity"
+		decompressed = b.transfer
+	case "gzip", "x-gzip":
+		b.compression = "gzip"
+		zr, err := gzip.NewReader(b.transfer)
+		if err != nil {
+			return nil, classifyFetchError(err)
+		}
+		decompressed = zr
+	case "deflate":
+		// the deflate content coding is the zlib format
+		b.compression = "deflate"
+		zr, err := zlib.NewReader(b.transfer)
+		if err != nil {
+			return nil, classifyFetchError(err)
+		}
+		decompressed = zr
+	default:
+		return nil, &Error{Code: CodeFetchFailed, Message: fmt.Sprintf("the page uses the unsupported content encoding %q", coding)}
+	}
+
+	b.decoded = &limitedReader{r: decompressed, limit: maxDecompressedSize, err: tooLargeError("the decompressed page", maxDecompressedSize)}
+	b.Reader = b.decoded
+	return b, nil
+}
+
+// size reports the bytes read so far, which is the whole page once it has been read to the end
+func (b *pageBody) size() SizeInfo {
+	return SizeInfo{
+		Bytes:         b.decoded.n,
+		TransferBytes: b.transfer.n,
+		Compression:   b.compression,
+	}
+}
@@ -0,0 +1,140 @@
+package analyzer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// compress returns body compressed with the given content coding
+func compress(t *testing.T, coding, body string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch coding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "deflate":
+		w = zlib.NewWriter(&buf)
+	default:
+		return []byte(body)
+	}
+	if _, err := w.Write([]byte(body)); err != nil {
+		t.Fatalf("Failed to compress: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Failed to compress: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// Table-driven tests for the size limits and size reporting of AnalyzeURL
+func TestAnalyzeURLSize(t *testing.T) {
+	page := `<html><head><title>Size</title></head><body>` + strings.Repeat("<p>padding</p>", 100) + `</body></html>`
+
+	tests := []struct {
+		name                string
+		coding              string
+		chunked             bool
+		maxDocumentSize     int64
+		maxDecompressedSize int64
+		expectedErr         ErrorCode
+	}{
+		{
+			name:   "Identity",
+			coding: "identity",
+		},
+		{
+			name:   "Gzip",
+			coding: "gzip",
+		},
+		{
+			name:   "Deflate",
+			coding: "deflate",
+		},
+		{
+			name:            "ContentLengthTooLarge",
+			coding:          "identity",
+			maxDocumentSize: 100,
+			expectedErr:     CodeTooLarge,
+		},
+		{
+			name:            "ChunkedTooLarge",
+			coding:          "identity",
+			chunked:         true,
+			maxDocumentSize: 100,
+			expectedErr:     CodeTooLarge,
+		},
+		{
+			name:                "DecompressedTooLarge",
+			coding:              "gzip",
+			maxDecompressedSize: 1000,
+			expectedErr:         CodeTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := compress(t, tt.coding, page)
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.coding != "identity" && !strings.Contains(r.Header.Get("Accept-Encoding"), tt.coding) {
+					t.Errorf("Accept-Encoding = %q, want it to contain %q", r.Header.Get("Accept-Encoding"), tt.coding)
+				}
+				w.Header().Set("Content-Type", "text/html")
+				if tt.coding != "identity" {
+					w.Header().Set("Content-Encoding", tt.coding)
+				}
+				if tt.chunked {
+					// flushing before writing the body leaves the Content-Length unknown
+					w.(http.Flusher).Flush()
+				}
+				w.Write(body)
+			}))
+			defer ts.Close()
+
+			opts := DefaultOptions()
+			if tt.maxDocumentSize > 0 {
+				opts.MaxDocumentSize = tt.maxDocumentSize
+			}
+			if tt.maxDecompressedSize > 0 {
+				opts.MaxDecompressedSize = tt.maxDecompressedSize
+			}
+
+			result, err := New(opts).AnalyzeURL(context.Background(), ts.URL)
+			if tt.expectedErr != "" {
+				if code := ErrorCodeOf(err); code != tt.expectedErr {
+					t.Fatalf("AnalyzeURL() error = %v, want a %v error", err, tt.expectedErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AnalyzeURL() error = %v", err)
+			}
+
+			expected := SizeInfo{Bytes: int64(len(page)), TransferBytes: int64(len(body)), Compression: tt.coding}
+			if result.Size != expected {
+				t.Errorf("Size = %+v, want %+v", result.Size, expected)
+			}
+			if result.PageTitle != "Size" {
+				t.Errorf("PageTitle = %v, want Size", result.PageTitle)
+			}
+		})
+	}
+}
+
+func TestLimitedReaderExactLimit(t *testing.T) {
+	r := &limitedReader{r: strings.NewReader("12345"), limit: 5, err: tooLargeError("the page", 5)}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v, want none for a body of exactly the limit", err)
+	}
+	if string(data) != "12345" || r.n != 5 {
+		t.Errorf("ReadAll() = %q after %d bytes, want 12345 after 5", data, r.n)
+	}
+}
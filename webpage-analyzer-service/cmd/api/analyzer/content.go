@@ -0,0 +1,75 @@
+package analyzer
+
+import (
+	"bufio"
+	"bytes"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// sniffLen is the number of bytes http.DetectContentType looks at
+const sniffLen = 512
+
+// htmlMediaTypes are the media types the analyzer parses as HTML
+var htmlMediaTypes = map[string]bool{
+	"text/html":             true,
+	"application/xhtml+xml": true,
+}
+
+// detectContentType returns the media type of the page, based on the Content-Type
+// header and the first bytes of body. Servers often omit the header or send
+// application/octet-stream, so those bodies are sniffed. An HTML header is trusted
+// unless the body is clearly binary. It returns a not_html error for other documents.
+func detectContentType(header http.Header, body *bufio.Reader) (string, error) {
+	// a short body is fine, Peek returns whatever there is
+	head, _ := body.Peek(sniffLen)
+	sniffed := sniffContentType(head)
+
+	declared := ""
+	if contentType := header.Get("Content-Type"); contentType != "" {
+		if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+			declared = mediaType
+		}
+	}
+
+	switch {
+	case htmlMediaTypes[declared]:
+		if isBinaryMediaType(sniffed) {
+			return "", notHTMLError(sniffed)
+		}
+		return declared, nil
+	case declared == "" || declared == "application/octet-stream":
+		if htmlMediaTypes[sniffed] {
+			return sniffed, nil
+		}
+		return "", notHTMLError(sniffed)
+	default:
+		return "", notHTMLError(declared)
+	}
+}
+
+// sniffContentType returns the media type of a body starting with head. Unlike
+// http.DetectContentType it recognizes XHTML documents that start with an XML declaration.
+func sniffContentType(head []byte) string {
+	mediaType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	if mediaType == "text/xml" && bytes.Contains(bytes.ToLower(head), []byte("<html")) {
+		return "application/xhtml+xml"
+	}
+	return mediaType
+}
+
+// isBinaryMediaType reports whether mediaType can never be mistaken for markup
+func isBinaryMediaType(mediaType string) bool {
+	for _, prefix := range []string{"image/", "audio/", "video/", "font/"} {
+		if strings.HasPrefix(mediaType, prefix) {
+			return true
+		}
+	}
+	switch mediaType {
+	case "application/pdf", "application/zip", "application/x-gzip", "application/x-rar-compressed",
+		"application/wasm", "application/vnd.ms-fontobject", "application/postscript":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package analyzer
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Table-driven tests for detectContentType
+func TestDetectContentType(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		expected    string
+		expectedErr bool
+	}{
+		{
+			name:        "HTML",
+			contentType: "text/html; charset=utf-8",
+			body:        `<!DOCTYPE html><html></html>`,
+			expected:    "text/html",
+		},
+		{
+			name:        "XHTML",
+			contentType: "application/xhtml+xml",
+			body:        `<?xml version="1.0"?><html xmlns="http://www.w3.org/1999/xhtml"></html>`,
+			expected:    "application/xhtml+xml",
+		},
+		{
+			name:     "MissingHeaderSniffsHTML",
+			body:     `<html><head><title>Test</title></head></html>`,
+			expected: "text/html",
+		},
+		{
+			name:     "MissingHeaderSniffsXHTML",
+			body:     `<?xml version="1.0" encoding="UTF-8"?><html xmlns="http://www.w3.org/1999/xhtml"></html>`,
+			expected: "application/xhtml+xml",
+		},
+		{
+			name:        "OctetStreamSniffsHTML",
+			contentType: "application/octet-stream",
+			body:        `<!DOCTYPE html><html></html>`,
+			expected:    "text/html",
+		},
+		{
+			name:        "JSON",
+			contentType: "application/json",
+			body:        `{"html": "<p>"}`,
+			expectedErr: true,
+		},
+		{
+			name:        "Image",
+			contentType: "image/png",
+			body:        "\x89PNG\r\n\x1a\n",
+			expectedErr: true,
+		},
+		{
+			name:        "PDFServedAsHTML",
+			contentType: "text/html",
+			body:        "%PDF-1.7\n",
+			expectedErr: true,
+		},
+		{
+			name:        "PlainText",
+			contentType: "text/plain",
+			body:        `<html></html>`,
+			expectedErr: true,
+		},
+		{
+			name:        "MissingHeaderSniffsText",
+			body:        `just some text`,
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.contentType != "" {
+				header.Set("Content-Type", tt.contentType)
+			}
+			got, err := detectContentType(header, bufio.NewReader(strings.NewReader(tt.body)))
+			if tt.expectedErr {
+				if ErrorCodeOf(err) != CodeNotHTML {
+					t.Errorf("detectContentType() error = %v, want a %v error", err, CodeNotHTML)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("detectContentType() error = %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("detectContentType() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAnalyzeURLXHTML(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xhtml+xml")
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Strict//EN" "http://www.w3.org/TR/xhtml1/DTD/xhtml1-strict.dtd">
+<html xmlns="http://www.w3.org/1999/xhtml"><head><title>XHTML Page</title></head><body><h1>Heading</h1></body></html>`))
+	}))
+	defer ts.Close()
+
+	result, err := New(DefaultOptions()).AnalyzeURL(context.Background(), ts.URL)
+	if err != nil {
+		t.Fatalf("AnalyzeURL() error = %v", err)
+	}
+	if result.ContentType != "application/xhtml+xml" {
+		t.Errorf("ContentType = %v, want application/xhtml+xml", result.ContentType)
+	}
+	if result.HTMLVersion != "XHTML 1.0 Strict" {
+		t.Errorf("HTMLVersion = %v, want XHTML 1.0 Strict", result.HTMLVersion)
+	}
+	if result.PageTitle != "XHTML Page" {
+		t.Errorf("PageTitle = %v, want XHTML Page", result.PageTitle)
+	}
+}
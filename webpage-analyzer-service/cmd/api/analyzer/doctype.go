@@ -0,0 +1,241 @@
+package analyzer
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Rendering modes browsers choose based on the doctype, see
+// https://html.spec.whatwg.org/multipage/parsing.html#the-initial-insertion-mode
+const (
+	RenderingModeStandards       = "standards"
+	RenderingModeAlmostStandards = "almost-standards"
+	RenderingModeQuirks          = "quirks"
+)
+
+// DoctypeInfo describes the doctype of the page
+type DoctypeInfo struct {
+	// Present reports whether the page has a doctype at all
+	Present bool `json:"present"`
+	// Name is the root element the doctype names, "html" for HTML documents
+	Name     string `json:"name,omitempty"`
+	PublicID string `json:"publicId,omitempty"`
+	SystemID string `json:"systemId,omitempty"`
+	// RenderingMode is the mode a browser would render the page in
+	RenderingMode string `json:"renderingMode"`
+}
+
+// knownDoctypes maps public identifiers, without their language suffix such as
+// "//EN", to versions. Taken from https://www.w3.org/QA/2002/04/valid-dtd-list.html
+// plus the older HTML 2.0 to 4.0 identifiers.
+var knownDoctypes = []struct {
+	publicID string
+	version  string
+}{
+	{"-//W3C//DTD HTML 4.01", "HTML 4.01 Strict"},
+	{"-//W3C//DTD HTML 4.01 Transitional", "HTML 4.01 Transitional"},
+	{"-//W3C//DTD HTML 4.01 Frameset", "HTML 4.01 Frameset"},
+	{"-//W3C//DTD HTML 4.0", "HTML 4.0 Strict"},
+	{"-//W3C//DTD HTML 4.0 Transitional", "HTML 4.0 Transitional"},
+	{"-//W3C//DTD HTML 4.0 Frameset", "HTML 4.0 Frameset"},
+	{"-//W3C//DTD HTML 3.2 Final", "HTML 3.2"},
+	{"-//W3C//DTD HTML 3.2", "HTML 3.2"},
+	{"-//IETF//DTD HTML 2.0", "HTML 2.0"},
+	{"-//IETF//DTD HTML", "HTML 2.0"},
+	{"-//W3C//DTD XHTML 1.0 Strict", "XHTML 1.0 Strict"},
+	{"-//W3C//DTD XHTML 1.0 Transitional", "XHTML 1.0 Transitional"},
+	{"-//W3C//DTD XHTML 1.0 Frameset", "XHTML 1.0 Frameset"},
+	{"-//W3C//DTD XHTML 1.1", "XHTML 1.1"},
+	{"-//W3C//DTD XHTML Basic 1.0", "XHTML Basic 1.0"},
+	{"-//W3C//DTD XHTML Basic 1.1", "XHTML Basic 1.1"},
+	{"-//W3C//DTD XHTML-Print 1.0", "XHTML Print 1.0"},
+	{"-//WAPFORUM//DTD XHTML Mobile 1.0", "XHTML Mobile Profile 1.0"},
+	{"-//WAPFORUM//DTD XHTML Mobile 1.1", "XHTML Mobile Profile 1.1"},
+	{"-//WAPFORUM//DTD XHTML Mobile 1.2", "XHTML Mobile Profile 1.2"},
+	{"-//W3C//DTD XHTML+RDFa 1.0", "XHTML+RDFa 1.0"},
+	{"-//W3C//DTD XHTML+RDFa 1.1", "XHTML+RDFa 1.1"},
+	{"-//W3C//DTD XHTML 1.1 plus MathML 2.0", "XHTML 1.1 plus MathML 2.0"},
+	{"-//W3C//DTD XHTML 1.1 plus MathML 2.0 plus SVG 1.1", "XHTML 1.1 plus MathML 2.0 plus SVG 1.1"},
+	{"-//W3C//DTD MathML 2.0", "MathML 2.0"},
+	{"-//W3C//DTD SVG 1.0", "SVG 1.0"},
+	{"-//W3C//DTD SVG 1.1", "SVG 1.1 Full"},
+	{"-//W3C//DTD SVG 1.1 Basic", "SVG 1.1 Basic"},
+	{"-//W3C//DTD SVG 1.1 Tiny", "SVG 1.1 Tiny"},
+}
+
+// knownSystemDoctypes maps system identifiers of doctypes without a public identifier to versions
+var knownSystemDoctypes = map[string]string{
+	"about:legacy-compat":                           "HTML5 (legacy-compat)",
+	"http://www.w3.org/math/dtd/mathml1/mathml.dtd": "MathML 1.01",
+}
+
+// quirksPublicIDPrefixes trigger quirks mode, see
+// https://html.spec.whatwg.org/multipage/parsing.html#the-initial-insertion-mode
+var quirksPublicIDPrefixes = []string{
+	"+//silmaril//dtd html pro v0r11 19970101//",
+	"-//as//dtd html 3.0 aswedit + extensions//",
+	"-//advasoft ltd//dtd html 3.0 aswedit + extensions//",
+	"-//ietf//dtd html 2.0 level 1//",
+	"-//ietf//dtd html 2.0 level 2//",
+	"-//ietf//dtd html 2.0 strict level 1//",
+	"-//ietf//dtd html 2.0 strict level 2//",
+	"-//ietf//dtd html 2.0 strict//",
+	"-//ietf//dtd html 2.0//",
+	"-//ietf//dtd html 2.1e//",
+	"-//ietf//dtd html 3.0//",
+	"-//ietf//dtd html 3.2 final//",
+	"-//ietf//dtd html 3.2//",
+	"-//ietf//dtd html 3//",
+	"-//ietf//dtd html level 0//",
+	"-//ietf//dtd html level 1//",
+	"-//ietf//dtd html level 2//",
+	"-//ietf//dtd html level 3//",
+	"-//ietf//dtd html strict level 0//",
+	"-//ietf//dtd html strict level 1//",
+	"-//ietf//dtd html strict level 2//",
+	"-//ietf//dtd html strict level 3//",
+	"-//ietf//dtd html strict//",
+	"-//ietf//dtd html//",
+	"-//metrius//dtd metrius presentational//",
+	"-//microsoft//dtd internet explorer 2.0 html strict//",
+	"-//microsoft//dtd internet explorer 2.0 html//",
+	"-//microsoft//dtd internet explorer 2.0 tables//",
+	"-//microsoft//dtd internet explorer 3.0 html strict//",
+	"-//microsoft//dtd internet explorer 3.0 html//",
+	"-//microsoft//dtd internet explorer 3.0 tables//",
+	"-//netscape comm. corp.//dtd html//",
+	"-//netscape comm. corp.//dtd strict html//",
+	"-//o'reilly and associates//dtd html 2.0//",
+	"-//o'reilly and associates//dtd html extended 1.0//",
+	"-//o'reilly and associates//dtd html extended relaxed 1.0//",
+	"-//sq//dtd html 2.0 hotmetal + extensions//",
+	"-//softquad software//dtd hotmetal pro 6.0::19990601::extensions to html 4.0//",
+	"-//softquad//dtd hotmetal pro 4.0::19971010::extensions to html 4.0//",
+	"-//spyglass//dtd html 2.0 extended//",
+	"-//sun microsystems corp.//dtd hotjava html//",
+	"-//sun microsystems corp.//dtd hotjava strict html//",
+	"-//w3c//dtd html 3 1995-03-24//",
+	"-//w3c//dtd html 3.2 draft//",
+	"-//w3c//dtd html 3.2 final//",
+	"-//w3c//dtd html 3.2//",
+	"-//w3c//dtd html 3.2s draft//",
+	"-//w3c//dtd html 4.0 frameset//",
+	"-//w3c//dtd html 4.0 transitional//",
+	"-//w3c//dtd html experimental 19960712//",
+	"-//w3c//dtd html experimental 970421//",
+	"-//w3c//dtd w3 html//",
+	"-//w3o//dtd w3 html 3.0//",
+	"-//webtechs//dtd mozilla html 2.0//",
+	"-//webtechs//dtd mozilla html//",
+}
+
+// findDoctype returns the doctype node of doc, or nil if there is none
+func findDoctype(doc *html.Node) *html.Node {
+	if doc.Type == html.DoctypeNode {
+		return doc
+	}
+	// the doctype may follow comments, e.g. the XML declaration of an XHTML document
+	for c := doc.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.DoctypeNode {
+			return c
+		}
+	}
+	return nil
+}
+
+// doctypeIDs returns the public and system identifiers of the doctype node n
+func doctypeIDs(n *html.Node) (publicID, systemID string) {
+	for _, attr := range n.Attr {
+		switch attr.Key {
+		case "public":
+			publicID = attr.Val
+		case "system":
+			systemID = attr.Val
+		}
+	}
+	return publicID, systemID
+}
+
+// getHTMLVersion determines the version of the HTML document by inspecting the doctype.
+// It returns "No DOCTYPE" when there is none and "Unknown" when it is not recognized.
+func getHTMLVersion(doc *html.Node) string {
+	n := findDoctype(doc)
+	if n == nil {
+		return "No DOCTYPE"
+	}
+
+	publicID, systemID := doctypeIDs(n)
+	publicID = strings.ToLower(strings.TrimSpace(publicID))
+	systemID = strings.ToLower(strings.TrimSpace(systemID))
+
+	if publicID == "" {
+		if strings.EqualFold(n.Data, "html") && systemID == "" {
+			return "HTML5"
+		}
+		if version, ok := knownSystemDoctypes[systemID]; ok {
+			return version
+		}
+		return "Unknown"
+	}
+
+	for _, known := range knownDoctypes {
+		// the identifier ends with a language such as //EN, which does not change the version
+		id := strings.ToLower(known.publicID)
+		if publicID == id || strings.HasPrefix(publicID, id+"//") {
+			return known.version
+		}
+	}
+	return "Unknown"
+}
+
+// getDoctype returns the doctype of doc and the rendering mode it puts browsers in
+func getDoctype(doc *html.Node) DoctypeInfo {
+	n := findDoctype(doc)
+	if n == nil {
+		return DoctypeInfo{RenderingMode: RenderingModeQuirks}
+	}
+
+	publicID, systemID := doctypeIDs(n)
+	return DoctypeInfo{
+		Present:       true,
+		Name:          n.Data,
+		PublicID:      publicID,
+		SystemID:      systemID,
+		RenderingMode: renderingMode(n.Data, publicID, systemID),
+	}
+}
+
+// renderingMode returns the mode browsers render a document with the given doctype in
+func renderingMode(name, publicID, systemID string) string {
+	hasSystemID := systemID != ""
+	publicID = strings.ToLower(publicID)
+	systemID = strings.ToLower(systemID)
+
+	if !strings.EqualFold(name, "html") {
+		return RenderingModeQuirks
+	}
+	switch publicID {
+	case "-//w3o//dtd w3 html strict 3.0//en//", "-/w3c/dtd html 4.0 transitional/en", "html":
+		return RenderingModeQuirks
+	}
+	if systemID == "http://www.ibm.com/data/dtd/v11/ibmxhtml1-transitional.dtd" {
+		return RenderingModeQuirks
+	}
+	for _, prefix := range quirksPublicIDPrefixes {
+		if strings.HasPrefix(publicID, prefix) {
+			return RenderingModeQuirks
+		}
+	}
+
+	html401 := strings.HasPrefix(publicID, "-//w3c//dtd html 4.01 frameset//") ||
+		strings.HasPrefix(publicID, "-//w3c//dtd html 4.01 transitional//")
+	if html401 && !hasSystemID {
+		return RenderingModeQuirks
+	}
+	if html401 || strings.HasPrefix(publicID, "-//w3c//dtd xhtml 1.0 frameset//") ||
+		strings.HasPrefix(publicID, "-//w3c//dtd xhtml 1.0 transitional//") {
+		return RenderingModeAlmostStandards
+	}
+	return RenderingModeStandards
+}
@@ -0,0 +1,103 @@
+package analyzer
+
+import "testing"
+
+// Table-driven tests for getDoctype
+func TestGetDoctype(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		expected DoctypeInfo
+	}{
+		{
+			name:     "HTML5",
+			html:     `<!DOCTYPE html><html></html>`,
+			expected: DoctypeInfo{Present: true, Name: "html", RenderingMode: RenderingModeStandards},
+		},
+		{
+			name:     "NoDoctype",
+			html:     `<html></html>`,
+			expected: DoctypeInfo{RenderingMode: RenderingModeQuirks},
+		},
+		{
+			name: "HTML401Strict",
+			html: `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01//EN" "http://www.w3.org/TR/html4/strict.dtd"><html></html>`,
+			expected: DoctypeInfo{
+				Present:       true,
+				Name:          "html",
+				PublicID:      "-//W3C//DTD HTML 4.01//EN",
+				SystemID:      "http://www.w3.org/TR/html4/strict.dtd",
+				RenderingMode: RenderingModeStandards,
+			},
+		},
+		{
+			name: "HTML401TransitionalWithSystemID",
+			html: `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01 Transitional//EN" "http://www.w3.org/TR/html4/loose.dtd"><html></html>`,
+			expected: DoctypeInfo{
+				Present:       true,
+				Name:          "html",
+				PublicID:      "-//W3C//DTD HTML 4.01 Transitional//EN",
+				SystemID:      "http://www.w3.org/TR/html4/loose.dtd",
+				RenderingMode: RenderingModeAlmostStandards,
+			},
+		},
+		{
+			name: "HTML401TransitionalWithoutSystemID",
+			html: `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01 Transitional//EN"><html></html>`,
+			expected: DoctypeInfo{
+				Present:       true,
+				Name:          "html",
+				PublicID:      "-//W3C//DTD HTML 4.01 Transitional//EN",
+				RenderingMode: RenderingModeQuirks,
+			},
+		},
+		{
+			name: "XHTML10Transitional",
+			html: `<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Transitional//EN" "http://www.w3.org/TR/xhtml1/DTD/xhtml1-transitional.dtd"><html></html>`,
+			expected: DoctypeInfo{
+				Present:       true,
+				Name:          "html",
+				PublicID:      "-//W3C//DTD XHTML 1.0 Transitional//EN",
+				SystemID:      "http://www.w3.org/TR/xhtml1/DTD/xhtml1-transitional.dtd",
+				RenderingMode: RenderingModeAlmostStandards,
+			},
+		},
+		{
+			name: "HTML32",
+			html: `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 3.2 Final//EN"><html></html>`,
+			expected: DoctypeInfo{
+				Present:       true,
+				Name:          "html",
+				PublicID:      "-//W3C//DTD HTML 3.2 Final//EN",
+				RenderingMode: RenderingModeQuirks,
+			},
+		},
+		{
+			name: "LegacyCompat",
+			html: `<!DOCTYPE html SYSTEM "about:legacy-compat"><html></html>`,
+			expected: DoctypeInfo{
+				Present:       true,
+				Name:          "html",
+				SystemID:      "about:legacy-compat",
+				RenderingMode: RenderingModeStandards,
+			},
+		},
+		{
+			name:     "OtherRootElement",
+			html:     `<!DOCTYPE something unknown><html></html>`,
+			expected: DoctypeInfo{Present: true, Name: "something", RenderingMode: RenderingModeQuirks},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parseHTML(tt.html)
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+			if got := getDoctype(doc); got != tt.expected {
+				t.Errorf("getDoctype() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package analyzer
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// getAttr returns the value of the attribute key of n and whether n has it
+func getAttr(n *html.Node, key string) (string, bool) {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return attr.Val, true
+		}
+	}
+	return "", false
+}
+
+// attrValue returns the value of the attribute key of n, or "" if n does not have it
+func attrValue(n *html.Node, key string) string {
+	val, _ := getAttr(n, key)
+	return val
+}
+
+// hasAttr reports whether n has the attribute key
+func hasAttr(n *html.Node, key string) bool {
+	_, ok := getAttr(n, key)
+	return ok
+}
+
+// resolveURL resolves the possibly relative reference ref against baseURL,
+// returning ref unchanged when it is not a valid URL
+func resolveURL(baseURL *url.URL, ref string) string {
+	u, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return ref
+	}
+	return baseURL.ResolveReference(u).String()
+}
+
+// textContent returns the text of n and its descendants with whitespace collapsed.
+// Images count with their alt text, as screen readers announce them.
+func textContent(n *html.Node) string {
+	var sb strings.Builder
+	var traverse func(*html.Node)
+	traverse = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			sb.WriteString(n.Data)
+		case n.Type == html.ElementNode && n.Data == "img":
+			sb.WriteString(" " + attrValue(n, "alt") + " ")
+		case n.Type == html.ElementNode && (n.Data == "script" || n.Data == "style"):
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			traverse(c)
+		}
+	}
+	traverse(n)
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
+
+// elementPath returns a CSS-like selector locating n from the root element,
+// such as html > body > div#main > p:nth-of-type(2)
+func elementPath(n *html.Node) string {
+	var segments []string
+	for ; n != nil && n.Type == html.ElementNode; n = n.Parent {
+		segment := n.Data
+		if id := attrValue(n, "id"); id != "" && !strings.ContainsAny(id, " \t\n") {
+			segment += "#" + id
+		}
+		if n.Parent != nil {
+			index, count := 0, 0
+			for s := n.Parent.FirstChild; s != nil; s = s.NextSibling {
+				if s.Type == html.ElementNode && s.Data == n.Data {
+					count++
+					if s == n {
+						index = count
+					}
+				}
+			}
+			if count > 1 {
+				segment += fmt.Sprintf(":nth-of-type(%d)", index)
+			}
+		}
+		segments = append(segments, segment)
+	}
+	slices.Reverse(segments)
+	return strings.Join(segments, " > ")
+}
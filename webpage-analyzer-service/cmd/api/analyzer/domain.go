@@ -0,0 +1,24 @@
+package analyzer
+
+import (
+	"net"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// registrableDomain returns the domain of host a registrant controls, e.g. example.co.uk for
+// www.example.co.uk, according to the public suffix list. IP addresses and hosts without
+// a registrable domain, such as localhost, are returned unchanged.
+func registrableDomain(host string) string {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	// the public suffix list would treat the last octets of an IPv4 address as a domain
+	if net.ParseIP(host) != nil {
+		return host
+	}
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return host
+	}
+	return domain
+}
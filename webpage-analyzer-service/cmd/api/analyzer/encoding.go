@@ -0,0 +1,179 @@
+package analyzer
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// prescanLen is how far into the page browsers look for a <meta charset>
+const prescanLen = 1024
+
+// EncodingInfo describes the character encoding of the page
+type EncodingInfo struct {
+	// Name is the encoding the page was decoded with, e.g. "utf-8" or "windows-1251"
+	Name string `json:"name"`
+	// Source is where the encoding was taken from: bom, header, meta, xml or default
+	Source string `json:"source"`
+	// HeaderCharset is the charset declared in the Content-Type header, if any
+	HeaderCharset string `json:"headerCharset,omitempty"`
+	// MetaCharset is the charset declared by a <meta> tag or the XML declaration, if any
+	MetaCharset string `json:"metaCharset,omitempty"`
+	// Mismatch reports that the header and the document declare different encodings
+	Mismatch bool `json:"mismatch"`
+}
+
+var boms = []struct {
+	bom  []byte
+	name string
+}{
+	{[]byte{0xef, 0xbb, 0xbf}, "utf-8"},
+	{[]byte{0xfe, 0xff}, "utf-16be"},
+	{[]byte{0xff, 0xfe}, "utf-16le"},
+}
+
+var xmlEncodingPattern = regexp.MustCompile(`encoding\s*=\s*["']([^"']+)["']`)
+
+// detectEncoding determines the encoding of a page starting with head the way
+// browsers do: a byte order mark wins over the Content-Type header, which wins
+// over a declaration in the document. Without any, UTF-8 is assumed when head is
+// valid UTF-8 and windows-1252 otherwise.
+func detectEncoding(header http.Header, head []byte) (encoding.Encoding, EncodingInfo) {
+	var info EncodingInfo
+	if _, params, err := mime.ParseMediaType(header.Get("Content-Type")); err == nil {
+		info.HeaderCharset = params["charset"]
+	}
+	metaCharset, metaSource := prescanCharset(head)
+	info.MetaCharset = metaCharset
+
+	headerEnc, headerName := charset.Lookup(info.HeaderCharset)
+	metaEnc, metaName := charset.Lookup(info.MetaCharset)
+	if headerEnc != nil && metaEnc != nil {
+		info.Mismatch = headerName != metaName
+	}
+
+	for _, b := range boms {
+		if bytes.HasPrefix(head, b.bom) {
+			e, name := charset.Lookup(b.name)
+			info.Name, info.Source = name, "bom"
+			return e, info
+		}
+	}
+
+	switch {
+	case headerEnc != nil:
+		info.Name, info.Source = headerName, "header"
+		return headerEnc, info
+	case metaEnc != nil:
+		// a document cannot declare itself UTF-16 in ASCII-compatible bytes
+		if strings.HasPrefix(metaName, "utf-16") {
+			metaEnc, metaName = charset.Lookup("utf-8")
+		}
+		info.Name, info.Source = metaName, metaSource
+		return metaEnc, info
+	}
+
+	name := "windows-1252"
+	if validUTF8Prefix(head) {
+		name = "utf-8"
+	}
+	e, name := charset.Lookup(name)
+	info.Name, info.Source = name, "default"
+	return e, info
+}
+
+// decodeBody returns a reader that transcodes r from e to UTF-8, dropping any byte order mark
+func decodeBody(r io.Reader, e encoding.Encoding) io.Reader {
+	return transform.NewReader(r, unicode.BOMOverride(e.NewDecoder()))
+}
+
+// prescanCharset returns the charset declared by the first <meta charset>,
+// <meta http-equiv="Content-Type"> or XML declaration in head, and which of them declared it
+func prescanCharset(head []byte) (string, string) {
+	if len(head) > prescanLen {
+		head = head[:prescanLen]
+	}
+
+	xmlCharset := ""
+	z := html.NewTokenizer(bytes.NewReader(head))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if xmlCharset != "" {
+				return xmlCharset, "xml"
+			}
+			return "", ""
+		case html.CommentToken:
+			// the tokenizer reads <?xml ...?> as a bogus comment
+			if data := string(z.Text()); xmlCharset == "" && strings.HasPrefix(data, "?xml") {
+				if m := xmlEncodingPattern.FindStringSubmatch(data); m != nil {
+					xmlCharset = m[1]
+				}
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			if string(name) != "meta" {
+				continue
+			}
+			var httpEquiv, content string
+			for hasAttr {
+				var key, val []byte
+				key, val, hasAttr = z.TagAttr()
+				switch string(key) {
+				case "charset":
+					return strings.TrimSpace(string(val)), "meta"
+				case "http-equiv":
+					httpEquiv = string(val)
+				case "content":
+					content = string(val)
+				}
+			}
+			if strings.EqualFold(httpEquiv, "content-type") {
+				if cs := charsetFromContent(content); cs != "" {
+					return cs, "meta"
+				}
+			}
+		}
+	}
+}
+
+// charsetFromContent extracts the charset from the content of a <meta http-equiv="Content-Type">
+func charsetFromContent(content string) string {
+	i := strings.Index(strings.ToLower(content), "charset")
+	if i < 0 {
+		return ""
+	}
+	value := strings.TrimSpace(content[i+len("charset"):])
+	value, ok := strings.CutPrefix(value, "=")
+	if !ok {
+		return ""
+	}
+	value = strings.Trim(strings.TrimSpace(value), `"'`)
+	if end := strings.IndexAny(value, "; \t\"'"); end >= 0 {
+		value = value[:end]
+	}
+	return value
+}
+
+// validUTF8Prefix reports whether head is valid UTF-8, ignoring a rune cut off at its end
+func validUTF8Prefix(head []byte) bool {
+	for i := len(head) - 1; i >= 0 && i > len(head)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(head[i]) {
+			if !utf8.FullRune(head[i:]) {
+				head = head[:i]
+			}
+			break
+		}
+	}
+	return utf8.Valid(head)
+}
@@ -0,0 +1,157 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+)
+
+// encode returns s encoded with e
+func encode(t *testing.T, e encoding.Encoding, s string) string {
+	t.Helper()
+	encoded, err := e.NewEncoder().String(s)
+	if err != nil {
+		t.Fatalf("Failed to encode %q: %v", s, err)
+	}
+	return encoded
+}
+
+// Table-driven tests for detectEncoding
+func TestDetectEncoding(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		head        string
+		expected    EncodingInfo
+	}{
+		{
+			name:        "Header",
+			contentType: "text/html; charset=windows-1251",
+			head:        `<html><head><title>Test</title></head></html>`,
+			expected:    EncodingInfo{Name: "windows-1251", Source: "header", HeaderCharset: "windows-1251"},
+		},
+		{
+			name:        "MetaCharset",
+			contentType: "text/html",
+			head:        `<html><head><meta charset="Shift_JIS"><title>Test</title></head></html>`,
+			expected:    EncodingInfo{Name: "shift_jis", Source: "meta", MetaCharset: "Shift_JIS"},
+		},
+		{
+			name:        "MetaHTTPEquiv",
+			contentType: "text/html",
+			head:        `<html><head><meta http-equiv="Content-Type" content="text/html; charset=ISO-8859-1"></head></html>`,
+			expected:    EncodingInfo{Name: "windows-1252", Source: "meta", MetaCharset: "ISO-8859-1"},
+		},
+		{
+			name:        "XMLDeclaration",
+			contentType: "application/xhtml+xml",
+			head:        `<?xml version="1.0" encoding="windows-1251"?><html xmlns="http://www.w3.org/1999/xhtml"></html>`,
+			expected:    EncodingInfo{Name: "windows-1251", Source: "xml", MetaCharset: "windows-1251"},
+		},
+		{
+			name:        "HeaderWinsOverMeta",
+			contentType: "text/html; charset=utf-8",
+			head:        `<html><head><meta charset="windows-1251"></head></html>`,
+			expected:    EncodingInfo{Name: "utf-8", Source: "header", HeaderCharset: "utf-8", MetaCharset: "windows-1251", Mismatch: true},
+		},
+		{
+			name:        "MatchingLabels",
+			contentType: "text/html; charset=latin1",
+			head:        `<html><head><meta charset="iso-8859-1"></head></html>`,
+			expected:    EncodingInfo{Name: "windows-1252", Source: "header", HeaderCharset: "latin1", MetaCharset: "iso-8859-1"},
+		},
+		{
+			name:        "BOMWinsOverHeader",
+			contentType: "text/html; charset=windows-1251",
+			head:        "\xef\xbb\xbf<html></html>",
+			expected:    EncodingInfo{Name: "utf-8", Source: "bom", HeaderCharset: "windows-1251"},
+		},
+		{
+			name:        "UnknownHeaderCharset",
+			contentType: "text/html; charset=no-such-charset",
+			head:        `<html><head><meta charset="koi8-r"></head></html>`,
+			expected:    EncodingInfo{Name: "koi8-r", Source: "meta", HeaderCharset: "no-such-charset", MetaCharset: "koi8-r"},
+		},
+		{
+			name:        "DefaultUTF8",
+			contentType: "text/html",
+			head:        "<html><head><title>Grüße</title></head></html>",
+			expected:    EncodingInfo{Name: "utf-8", Source: "default"},
+		},
+		{
+			name:        "DefaultWindows1252",
+			contentType: "text/html",
+			head:        "<html><head><title>Gr\xfc\xdfe</title></head></html>",
+			expected:    EncodingInfo{Name: "windows-1252", Source: "default"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			header.Set("Content-Type", tt.contentType)
+			_, got := detectEncoding(header, []byte(tt.head))
+			if got != tt.expected {
+				t.Errorf("detectEncoding() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+// Table-driven tests for transcoding pages to UTF-8 in AnalyzeURL
+func TestAnalyzeURLEncoding(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		expected    string
+	}{
+		{
+			name:        "Windows1251Header",
+			contentType: "text/html; charset=windows-1251",
+			body:        encode(t, charmap.Windows1251, "<html><head><title>Привет, мир</title></head></html>"),
+			expected:    "Привет, мир",
+		},
+		{
+			name:        "ShiftJISMeta",
+			contentType: "text/html",
+			body:        encode(t, japanese.ShiftJIS, `<html><head><meta charset="Shift_JIS"><title>こんにちは</title></head></html>`),
+			expected:    "こんにちは",
+		},
+		{
+			name:        "ISO88591Meta",
+			contentType: "text/html",
+			body:        encode(t, charmap.ISO8859_1, `<html><head><meta http-equiv="Content-Type" content="text/html; charset=iso-8859-1"><title>Café</title></head></html>`),
+			expected:    "Café",
+		},
+		{
+			name:        "UTF8BOM",
+			contentType: "text/html",
+			body:        "\xef\xbb\xbf<html><head><title>Grüße</title></head></html>",
+			expected:    "Grüße",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.Write([]byte(tt.body))
+			}))
+			defer ts.Close()
+
+			result, err := New(DefaultOptions()).AnalyzeURL(context.Background(), ts.URL)
+			if err != nil {
+				t.Fatalf("AnalyzeURL() error = %v", err)
+			}
+			if result.PageTitle != tt.expected {
+				t.Errorf("PageTitle = %q, want %q", result.PageTitle, tt.expected)
+			}
+		})
+	}
+}
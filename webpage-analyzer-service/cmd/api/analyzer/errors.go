@@ -0,0 +1,129 @@
+package analyzer
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+)
+
+// ErrorCode identifies why an analysis failed. Codes are stable and safe to match on.
+type ErrorCode string
+
+const (
+	// CodeInvalidURL means the URL is malformed or not http(s)
+	CodeInvalidURL ErrorCode = "invalid_url"
+	// CodeDNSFailure means the host name of the URL could not be resolved
+	CodeDNSFailure ErrorCode = "dns_failure"
+	// CodeConnectionRefused means the host refused the connection
+	CodeConnectionRefused ErrorCode = "connection_refused"
+	// CodeTLSError means the TLS handshake or certificate verification failed
+	CodeTLSError ErrorCode = "tls_error"
+	// CodeTimeout means the page did not respond in time
+	CodeTimeout ErrorCode = "timeout"
+	// CodeAnalysisTimeout means the analysis as a whole did not finish in time
+	CodeAnalysisTimeout ErrorCode = "analysis_timeout"
+	// CodeUpstreamStatus means the page responded with a status other than 200
+	CodeUpstreamStatus ErrorCode = "upstream_status"
+	// CodeNotHTML means the page is not an HTML document
+	CodeNotHTML ErrorCode = "not_html"
+	// CodeTooLarge means the page exceeds the configured size limits
+	CodeTooLarge ErrorCode = "too_large"
+	// CodeFetchFailed means fetching the page failed for any other reason
+	CodeFetchFailed ErrorCode = "fetch_failed"
+	// CodeRateLimited means the page's host has been requested too often, see RateLimitError
+	CodeRateLimited ErrorCode = "rate_limited"
+)
+
+// Error describes why an analysis failed
+type Error struct {
+	Code    ErrorCode
+	Message string
+	// UpstreamStatus is the status the page responded with, set for CodeUpstreamStatus
+	UpstreamStatus int
+	// Err is the underlying error, if any
+	Err error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ErrorCodeOf returns the code of the analysis error in err's chain, or "" if there is none
+func ErrorCodeOf(err error) ErrorCode {
+	var analysisErr *Error
+	if errors.As(err, &analysisErr) {
+		return analysisErr.Code
+	}
+	var rateLimitErr *RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		return CodeRateLimited
+	}
+	return ""
+}
+
+func invalidURLError(err error) *Error {
+	return &Error{Code: CodeInvalidURL, Message: fmt.Sprintf("invalid URL: %v", err), Err: err}
+}
+
+func upstreamStatusError(status int) *Error {
+	return &Error{
+		Code:           CodeUpstreamStatus,
+		Message:        fmt.Sprintf("the page responded with %d %s", status, http.StatusText(status)),
+		UpstreamStatus: status,
+	}
+}
+
+func notHTMLError(mediaType string) *Error {
+	return &Error{Code: CodeNotHTML, Message: fmt.Sprintf("the page is not an HTML document (%s)", mediaType)}
+}
+
+func tooLargeError(what string, limit int64) *Error {
+	return &Error{Code: CodeTooLarge, Message: fmt.Sprintf("%s is larger than %d bytes", what, limit)}
+}
+
+// classifyFetchError turns an error from fetching or reading the page into an *Error.
+// Cancellation by the caller is returned unchanged since it says nothing about the page.
+func classifyFetchError(err error) error {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	var analysisErr *Error
+	if errors.As(err, &analysisErr) {
+		return err
+	}
+
+	var dnsErr *net.DNSError
+	var certErr *tls.CertificateVerificationError
+	var recordErr tls.RecordHeaderError
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidCertErr x509.CertificateInvalidError
+	var netErr net.Error
+
+	switch {
+	case errors.As(err, &dnsErr):
+		if dnsErr.IsTimeout {
+			return &Error{Code: CodeTimeout, Message: fmt.Sprintf("resolving %s timed out", dnsErr.Name), Err: err}
+		}
+		return &Error{Code: CodeDNSFailure, Message: fmt.Sprintf("could not resolve %s", dnsErr.Name), Err: err}
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return &Error{Code: CodeConnectionRefused, Message: "the server refused the connection", Err: err}
+	case errors.As(err, &certErr), errors.As(err, &recordErr), errors.As(err, &unknownAuthorityErr),
+		errors.As(err, &hostnameErr), errors.As(err, &invalidCertErr):
+		return &Error{Code: CodeTLSError, Message: fmt.Sprintf("TLS error: %v", err), Err: err}
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return &Error{Code: CodeTimeout, Message: "the page did not respond in time", Err: err}
+	default:
+		return &Error{Code: CodeFetchFailed, Message: fmt.Sprintf("fetching the page failed: %v", err), Err: err}
+	}
+}
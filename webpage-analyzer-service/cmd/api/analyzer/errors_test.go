@@ -0,0 +1,141 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// closedPortURL returns the URL of a local port nothing listens on
+func closedPortURL(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+	return "http://" + addr
+}
+
+// Table-driven tests for the error codes of AnalyzeURL
+func TestAnalyzeURLErrorCodes(t *testing.T) {
+	notFound := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer notFound.Close()
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer slow.Close()
+
+	pdf := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write([]byte("%PDF-1.7\n"))
+	}))
+	defer pdf.Close()
+
+	untrusted := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html></html>`))
+	}))
+	defer untrusted.Close()
+
+	tests := []struct {
+		name           string
+		url            string
+		expected       ErrorCode
+		expectedStatus int
+		// fetchTimeout replaces the default fetch timeout when set
+		fetchTimeout time.Duration
+	}{
+		{
+			name:     "UnsupportedScheme",
+			url:      "ftp://www.example.com/file",
+			expected: CodeInvalidURL,
+		},
+		{
+			name:     "MissingHost",
+			url:      "http:///path",
+			expected: CodeInvalidURL,
+		},
+		{
+			name:     "DNSFailure",
+			url:      "http://does-not-exist.invalid",
+			expected: CodeDNSFailure,
+		},
+		{
+			name:     "ConnectionRefused",
+			url:      closedPortURL(t),
+			expected: CodeConnectionRefused,
+		},
+		{
+			name:     "TLSError",
+			url:      untrusted.URL,
+			expected: CodeTLSError,
+		},
+		{
+			name:         "Timeout",
+			url:          slow.URL,
+			expected:     CodeTimeout,
+			fetchTimeout: 100 * time.Millisecond,
+		},
+		{
+			name:           "UpstreamStatus",
+			url:            notFound.URL,
+			expected:       CodeUpstreamStatus,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:     "NotHTML",
+			url:      pdf.URL,
+			expected: CodeNotHTML,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			if tt.fetchTimeout > 0 {
+				opts.FetchTimeout = tt.fetchTimeout
+			}
+			_, err := New(opts).AnalyzeURL(context.Background(), tt.url)
+
+			var analysisErr *Error
+			if !errors.As(err, &analysisErr) {
+				t.Fatalf("AnalyzeURL() error = %v, want an *Error", err)
+			}
+			if analysisErr.Code != tt.expected {
+				t.Errorf("Code = %v, want %v (%v)", analysisErr.Code, tt.expected, err)
+			}
+			if analysisErr.UpstreamStatus != tt.expectedStatus {
+				t.Errorf("UpstreamStatus = %v, want %v", analysisErr.UpstreamStatus, tt.expectedStatus)
+			}
+		})
+	}
+}
+
+func TestAnalyzeURLCanceled(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html></html>`))
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := New(DefaultOptions()).AnalyzeURL(ctx, ts.URL)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("AnalyzeURL() error = %v, want context.Canceled", err)
+	}
+	if code := ErrorCodeOf(err); code != "" {
+		t.Errorf("ErrorCodeOf() = %v, want no code", code)
+	}
+}
@@ -0,0 +1,111 @@
+package analyzer
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Flags raised for forms that put the submitted data at risk
+const (
+	// FormFlagInsecureSubmit marks forms on https pages that submit to http
+	FormFlagInsecureSubmit = "insecure_submit"
+	// FormFlagPasswordOnHTTP marks forms with a password field on a page that is not served over https
+	FormFlagPasswordOnHTTP = "password_on_http"
+	// FormFlagThirdPartySubmit marks forms that submit to another site
+	FormFlagThirdPartySubmit = "third_party_submit"
+)
+
+// Form describes a <form> on the page
+type Form struct {
+	// Action is the resolved URL the form submits to
+	Action string `json:"action"`
+	// Method is the upper case submit method
+	Method       string      `json:"method"`
+	Fields       []FormField `json:"fields"`
+	HasCSRFToken bool        `json:"hasCsrfToken"`
+	Flags        []string    `json:"flags"`
+}
+
+// FormField is an input, select or textarea of a form
+type FormField struct {
+	Name string `json:"name"`
+	// Type is the input type, or select or textarea for those elements
+	Type string `json:"type"`
+}
+
+// csrfFieldNames are found in the names of hidden fields carrying anti-CSRF tokens
+var csrfFieldNames = []string{"csrf", "xsrf", "authenticity_token", "_token", "requestverificationtoken", "anti-forgery", "antiforgery", "nonce"}
+
+// getForms returns every form of the document with its fields, resolving actions against
+// baseURL and flagging forms that submit insecurely or to another site
+func getForms(doc *html.Node, baseURL *url.URL) []Form {
+	forms := []Form{}
+	var traverse func(*html.Node)
+	traverse = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "form" {
+			forms = append(forms, describeForm(n, baseURL))
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			traverse(c)
+		}
+	}
+	traverse(doc)
+	return forms
+}
+
+// describeForm lists the fields of form and flags how it submits them
+func describeForm(form *html.Node, baseURL *url.URL) Form {
+	f := Form{
+		Action: resolveURL(baseURL, attrValue(form, "action")),
+		Method: formMethod(form),
+		Fields: []FormField{},
+		Flags:  []string{},
+	}
+
+	hasPassword := false
+	var traverse func(*html.Node)
+	traverse = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.Data {
+			case "input":
+				field := FormField{Name: attrValue(n, "name"), Type: strings.ToLower(attrValue(n, "type"))}
+				if field.Type == "" {
+					field.Type = "text"
+				}
+				f.Fields = append(f.Fields, field)
+				hasPassword = hasPassword || field.Type == "password"
+				if field.Type == "hidden" && containsAny(strings.ToLower(field.Name), csrfFieldNames) {
+					f.HasCSRFToken = true
+				}
+			case "select", "textarea":
+				f.Fields = append(f.Fields, FormField{Name: attrValue(n, "name"), Type: n.Data})
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			traverse(c)
+		}
+	}
+	traverse(form)
+
+	action, err := url.Parse(f.Action)
+	if err != nil {
+		return f
+	}
+	if baseURL.Scheme == "https" && action.Scheme == "http" {
+		f.Flags = append(f.Flags, FormFlagInsecureSubmit)
+	}
+	if hasPassword && baseURL.Scheme != "https" {
+		f.Flags = append(f.Flags, FormFlagPasswordOnHTTP)
+	}
+	if (action.Scheme == "http" || action.Scheme == "https") && !isSameSiteHost(action.Hostname(), baseURL.Hostname()) {
+		f.Flags = append(f.Flags, FormFlagThirdPartySubmit)
+	}
+	return f
+}
+
+// isSameSiteHost reports whether the hosts share their registrable domain
+func isSameSiteHost(a, b string) bool {
+	return registrableDomain(normalizeHost(a)) == registrableDomain(normalizeHost(b))
+}
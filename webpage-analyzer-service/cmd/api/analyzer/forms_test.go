@@ -0,0 +1,96 @@
+package analyzer
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// Table-driven tests for getForms
+func TestGetForms(t *testing.T) {
+	tests := []struct {
+		name     string
+		pageURL  string
+		html     string
+		expected []Form
+	}{
+		{
+			name:     "NoForms",
+			pageURL:  "https://www.example.com/",
+			html:     `<html><body></body></html>`,
+			expected: []Form{},
+		},
+		{
+			name:    "FieldsAndCSRFToken",
+			pageURL: "https://www.example.com/contact/",
+			html: `<html><body>
+				<form action="send" method="post">
+					<input type="hidden" name="csrfmiddlewaretoken" value="abc">
+					<div><input name="name"><input type="email" name="email"></div>
+					<select name="topic"><option>Sales</option></select>
+					<textarea name="message"></textarea>
+					<button type="submit">Send</button>
+				</form>
+			</body></html>`,
+			expected: []Form{{
+				Action: "https://www.example.com/contact/send",
+				Method: "POST",
+				Fields: []FormField{
+					{Name: "csrfmiddlewaretoken", Type: "hidden"},
+					{Name: "name", Type: "text"},
+					{Name: "email", Type: "email"},
+					{Name: "topic", Type: "select"},
+					{Name: "message", Type: "textarea"},
+				},
+				HasCSRFToken: true,
+				Flags:        []string{},
+			}},
+		},
+		{
+			name:    "SameSiteSubdomainWithoutWWW",
+			pageURL: "https://www.example.com/",
+			html:    `<form action="https://example.com/search"><input name="q"></form>`,
+			expected: []Form{{
+				Action: "https://example.com/search",
+				Method: "GET",
+				Fields: []FormField{{Name: "q", Type: "text"}},
+				Flags:  []string{},
+			}},
+		},
+		{
+			name:    "InsecureSubmitToThirdParty",
+			pageURL: "https://www.example.com/",
+			html:    `<form action="http://forms.tracker.net/collect" method="post"><input type="email" name="email"></form>`,
+			expected: []Form{{
+				Action: "http://forms.tracker.net/collect",
+				Method: "POST",
+				Fields: []FormField{{Name: "email", Type: "email"}},
+				Flags:  []string{FormFlagInsecureSubmit, FormFlagThirdPartySubmit},
+			}},
+		},
+		{
+			name:    "PasswordOnHTTPPage",
+			pageURL: "http://www.example.com/",
+			html:    `<form action="/login" method="post"><input name="user"><input type="password" name="pass"></form>`,
+			expected: []Form{{
+				Action: "http://www.example.com/login",
+				Method: "POST",
+				Fields: []FormField{{Name: "user", Type: "text"}, {Name: "pass", Type: "password"}},
+				Flags:  []string{FormFlagPasswordOnHTTP},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseURL, _ := url.Parse(tt.pageURL)
+			doc, err := parseHTML(tt.html)
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+			if got := getForms(doc, baseURL); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("getForms() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package analyzer
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+// Flags raised for images that slow down the page or fail to load
+const (
+	// ImageFlagMissingDimensions marks images without width and height, which shift the layout as they load
+	ImageFlagMissingDimensions = "missing_dimensions"
+	// ImageFlagMissingLazyLoading marks images below the fold that are not loaded lazily
+	ImageFlagMissingLazyLoading = "missing_lazy_loading"
+	// ImageFlagOversized marks images with a source larger than oversizedImageBytes
+	ImageFlagOversized = "oversized"
+	// ImageFlagBroken marks images with a source that does not load
+	ImageFlagBroken = "broken"
+)
+
+// aboveFoldImages is how many images the page is assumed to show before scrolling
+const aboveFoldImages = 3
+
+// oversizedImageBytes is the size beyond which an image should be compressed or resized
+const oversizedImageBytes = 200 << 10
+
+// Image is an <img> together with the sources the browser may pick from
+type Image struct {
+	// Element is a CSS-like path to the <img>
+	Element string `json:"element"`
+	Alt     string `json:"alt"`
+	// Loading is the loading attribute, lazy or eager
+	Loading string        `json:"loading,omitempty"`
+	Sources []ImageSource `json:"sources"`
+	Flags   []string      `json:"flags"`
+}
+
+// ImageSource is a URL an image may be loaded from
+type ImageSource struct {
+	// URL is resolved against the page
+	URL string `json:"url"`
+	// Attribute is where the URL was found: src, srcset, or source for a <picture> <source>
+	Attribute string `json:"attribute"`
+	// Descriptor is the width or density descriptor of a srcset candidate, e.g. 640w or 2x
+	Descriptor string `json:"descriptor,omitempty"`
+	// Probe is nil for sources that were not requested, such as data URLs
+	Probe *Probe `json:"probe,omitempty"`
+}
+
+// srcsetCandidate is an image candidate string of a srcset attribute
+type srcsetCandidate struct {
+	url        string
+	descriptor string
+}
+
+// getImages returns the images of the document with their sources resolved against baseURL
+// and flags their markup calls for. Sources are not probed yet.
+func getImages(doc *html.Node, baseURL *url.URL) []Image {
+	images := []Image{}
+	var traverse func(*html.Node)
+	traverse = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "img" {
+			images = append(images, describeImage(n, baseURL, len(images) >= aboveFoldImages))
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			traverse(c)
+		}
+	}
+	traverse(doc)
+	return images
+}
+
+// describeImage lists the sources of img, including the <source> elements of an enclosing <picture>
+func describeImage(img *html.Node, baseURL *url.URL, belowFold bool) Image {
+	image := Image{
+		Element: elementPath(img),
+		Alt:     attrValue(img, "alt"),
+		Loading: strings.ToLower(strings.TrimSpace(attrValue(img, "loading"))),
+		Sources: []ImageSource{},
+		Flags:   []string{},
+	}
+	add := func(attribute string, candidates ...srcsetCandidate) {
+		for _, c := range candidates {
+			image.Sources = append(image.Sources, ImageSource{URL: resolveURL(baseURL, c.url), Attribute: attribute, Descriptor: c.descriptor})
+		}
+	}
+
+	if img.Parent != nil && img.Parent.Data == "picture" {
+		for s := img.Parent.FirstChild; s != nil; s = s.NextSibling {
+			if s.Type == html.ElementNode && s.Data == "source" {
+				add("source", parseSrcset(attrValue(s, "srcset"))...)
+			}
+		}
+	}
+	add("srcset", parseSrcset(attrValue(img, "srcset"))...)
+	if src := strings.TrimSpace(attrValue(img, "src")); src != "" {
+		add("src", srcsetCandidate{url: src})
+	}
+
+	_, hasWidth := getAttr(img, "width")
+	_, hasHeight := getAttr(img, "height")
+	if !hasWidth || !hasHeight {
+		image.Flags = append(image.Flags, ImageFlagMissingDimensions)
+	}
+	if belowFold && image.Loading != "lazy" {
+		image.Flags = append(image.Flags, ImageFlagMissingLazyLoading)
+	}
+	return image
+}
+
+// checkImages probes the sources of images and flags oversized and broken images
+func (a *Analyzer) checkImages(ctx context.Context, images []Image) {
+	var links []string
+	for _, image := range images {
+		for _, source := range image.Sources {
+			links = append(links, source.URL)
+		}
+	}
+	probes := a.probeResources(ctx, links)
+
+	for i := range images {
+		image := &images[i]
+		oversized, broken := false, false
+		for j := range image.Sources {
+			p, ok := probes[image.Sources[j].URL]
+			if !ok {
+				continue
+			}
+			image.Sources[j].Probe = &p
+			oversized = oversized || p.Bytes > oversizedImageBytes
+			// servers answering missing images with an HTML error page are common
+			broken = broken || !p.OK() || p.ContentType == "text/html"
+		}
+		if oversized {
+			image.Flags = append(image.Flags, ImageFlagOversized)
+		}
+		if broken {
+			image.Flags = append(image.Flags, ImageFlagBroken)
+		}
+	}
+}
+
+// parseSrcset splits a srcset attribute into its candidates,
+// see https://html.spec.whatwg.org/multipage/images.html#parsing-a-srcset-attribute
+func parseSrcset(srcset string) []srcsetCandidate {
+	var candidates []srcsetCandidate
+	s := srcset
+	for {
+		s = strings.TrimLeftFunc(s, func(r rune) bool { return unicode.IsSpace(r) || r == ',' })
+		if s == "" {
+			return candidates
+		}
+		end := strings.IndexFunc(s, unicode.IsSpace)
+		if end < 0 {
+			end = len(s)
+		}
+		c := srcsetCandidate{url: s[:end]}
+		s = s[end:]
+
+		// a URL ending with a comma has no descriptor
+		if trimmed := strings.TrimRight(c.url, ","); trimmed != c.url {
+			c.url = trimmed
+		} else {
+			// the descriptor runs up to the next comma outside of parentheses
+			depth, i := 0, 0
+			for ; i < len(s); i++ {
+				if s[i] == '(' {
+					depth++
+				} else if s[i] == ')' && depth > 0 {
+					depth--
+				} else if s[i] == ',' && depth == 0 {
+					break
+				}
+			}
+			c.descriptor = strings.Join(strings.Fields(s[:i]), " ")
+			s = s[i:]
+		}
+		if c.url != "" {
+			candidates = append(candidates, c)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// Table-driven tests for parseSrcset
+func TestParseSrcset(t *testing.T) {
+	tests := []struct {
+		name     string
+		srcset   string
+		expected []srcsetCandidate
+	}{
+		{name: "Empty", srcset: "", expected: nil},
+		{name: "SingleURL", srcset: "/a.png", expected: []srcsetCandidate{{url: "/a.png"}}},
+		{
+			name:     "Densities",
+			srcset:   "/a.png 1x, /a@2x.png 2x",
+			expected: []srcsetCandidate{{url: "/a.png", descriptor: "1x"}, {url: "/a@2x.png", descriptor: "2x"}},
+		},
+		{
+			name:     "WidthsWithoutSpaces",
+			srcset:   "/small.jpg 320w,/large.jpg   1024w",
+			expected: []srcsetCandidate{{url: "/small.jpg", descriptor: "320w"}, {url: "/large.jpg", descriptor: "1024w"}},
+		},
+		{
+			name:     "CommaInURL",
+			srcset:   "/img.php?size=1,2 1x, /b.png,",
+			expected: []srcsetCandidate{{url: "/img.php?size=1,2", descriptor: "1x"}, {url: "/b.png"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseSrcset(tt.srcset); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("parseSrcset(%q) = %+v, want %+v", tt.srcset, got, tt.expected)
+			}
+		})
+	}
+}
+
+// Table-driven tests for getImages
+func TestGetImages(t *testing.T) {
+	baseURL, _ := url.Parse("https://example.com/gallery/")
+
+	tests := []struct {
+		name     string
+		html     string
+		expected []Image
+	}{
+		{
+			name:     "NoImages",
+			html:     `<html><body><p>Text</p></body></html>`,
+			expected: []Image{},
+		},
+		{
+			name: "ImageWithDimensions",
+			html: `<html><body><img src="cat.jpg" alt="Cat" width="640" height="480"></body></html>`,
+			expected: []Image{{
+				Element: "html > body > img",
+				Alt:     "Cat",
+				Sources: []ImageSource{{URL: "https://example.com/gallery/cat.jpg", Attribute: "src"}},
+				Flags:   []string{},
+			}},
+		},
+		{
+			name: "PictureWithSrcset",
+			html: `<html><body><picture>
+				<source srcset="/cat.avif 1x, /cat@2x.avif 2x" type="image/avif">
+				<img src="/cat.jpg" srcset="/cat-640.jpg 640w" alt="Cat">
+			</picture></body></html>`,
+			expected: []Image{{
+				Element: "html > body > picture > img",
+				Alt:     "Cat",
+				Sources: []ImageSource{
+					{URL: "https://example.com/cat.avif", Attribute: "source", Descriptor: "1x"},
+					{URL: "https://example.com/cat@2x.avif", Attribute: "source", Descriptor: "2x"},
+					{URL: "https://example.com/cat-640.jpg", Attribute: "srcset", Descriptor: "640w"},
+					{URL: "https://example.com/cat.jpg", Attribute: "src"},
+				},
+				Flags: []string{ImageFlagMissingDimensions},
+			}},
+		},
+		{
+			name: "BelowTheFold",
+			html: `<html><body>
+				<img src="1.png" width="1" height="1"><img src="2.png" width="1" height="1"><img src="3.png" width="1" height="1">
+				<img src="4.png" width="1" height="1"><img src="5.png" width="1" height="1" loading="lazy">
+			</body></html>`,
+			expected: []Image{
+				{Element: "html > body > img:nth-of-type(1)", Sources: []ImageSource{{URL: "https://example.com/gallery/1.png", Attribute: "src"}}, Flags: []string{}},
+				{Element: "html > body > img:nth-of-type(2)", Sources: []ImageSource{{URL: "https://example.com/gallery/2.png", Attribute: "src"}}, Flags: []string{}},
+				{Element: "html > body > img:nth-of-type(3)", Sources: []ImageSource{{URL: "https://example.com/gallery/3.png", Attribute: "src"}}, Flags: []string{}},
+				{Element: "html > body > img:nth-of-type(4)", Sources: []ImageSource{{URL: "https://example.com/gallery/4.png", Attribute: "src"}}, Flags: []string{ImageFlagMissingLazyLoading}},
+				{Element: "html > body > img:nth-of-type(5)", Loading: "lazy", Sources: []ImageSource{{URL: "https://example.com/gallery/5.png", Attribute: "src"}}, Flags: []string{}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parseHTML(tt.html)
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+			if got := getImages(doc, baseURL); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("getImages() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCheckImages(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/small.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(make([]byte, 1024))
+		case "/large.jpg":
+			w.Header().Set("Content-Type", "image/jpeg")
+			w.Write(make([]byte, oversizedImageBytes+1))
+		case "/soft404.png":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(strings.Repeat("<p>Not found</p>", 10)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	images := []Image{
+		{Sources: []ImageSource{{URL: ts.URL + "/small.png"}}, Flags: []string{}},
+		{Sources: []ImageSource{{URL: ts.URL + "/small.png"}, {URL: ts.URL + "/large.jpg"}}, Flags: []string{}},
+		{Sources: []ImageSource{{URL: ts.URL + "/missing.png"}}, Flags: []string{}},
+		{Sources: []ImageSource{{URL: ts.URL + "/soft404.png"}}, Flags: []string{}},
+		{Sources: []ImageSource{{URL: "data:image/gif;base64,R0lGODlhAQABAAAAACw="}}, Flags: []string{}},
+	}
+	New(DefaultOptions()).checkImages(context.Background(), images)
+
+	expectedFlags := [][]string{{}, {ImageFlagOversized}, {ImageFlagBroken}, {ImageFlagBroken}, {}}
+	for i, image := range images {
+		if !slices.Equal(image.Flags, expectedFlags[i]) {
+			t.Errorf("image %d: Flags = %v, want %v", i, image.Flags, expectedFlags[i])
+		}
+	}
+	if p := images[0].Sources[0].Probe; p == nil || *p != (Probe{Status: http.StatusOK, ContentType: "image/png", Bytes: 1024}) {
+		t.Errorf("Probe = %+v, want 200 image/png of 1024 bytes", p)
+	}
+	if p := images[4].Sources[0].Probe; p != nil {
+		t.Errorf("data URL was probed: %+v", p)
+	}
+}
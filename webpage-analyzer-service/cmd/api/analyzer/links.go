@@ -0,0 +1,100 @@
+package analyzer
+
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Scopes of links relative to the analyzed page
+const (
+	LinkScopeSameHost = "same_host"
+	// LinkScopeSameSite is another host of the same registrable domain, or a configured internal host
+	LinkScopeSameSite = "same_site"
+	LinkScopeExternal = "external"
+)
+
+// defaultPorts are left out when comparing hosts
+var defaultPorts = map[string]string{"http": "80", "https": "443"}
+
+// LinkScopes counts the links of the page by scope
+type LinkScopes struct {
+	SameHost int `json:"sameHost"`
+	SameSite int `json:"sameSite"`
+	External int `json:"external"`
+}
+
+// getLinkScopes counts the links of the document by their scope relative to baseURL
+func getLinkScopes(doc *html.Node, baseURL *url.URL, internalHosts []string) LinkScopes {
+	var scopes LinkScopes
+	var traverse func(*html.Node)
+	traverse = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "a" {
+			if href, ok := getAttr(n, "href"); ok {
+				switch linkScope(href, baseURL, internalHosts) {
+				case LinkScopeSameHost:
+					scopes.SameHost++
+				case LinkScopeSameSite:
+					scopes.SameSite++
+				default:
+					scopes.External++
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			traverse(c)
+		}
+	}
+	traverse(doc)
+	return scopes
+}
+
+// linkScope classifies link relative to the page at baseURL. Hosts are compared case-insensitively
+// without default ports, and other hosts of the page's registrable domain or of internalHosts
+// belong to the same site. Links without a host, including invalid ones, are on the same host.
+func linkScope(link string, baseURL *url.URL, internalHosts []string) string {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || u.Host == "" {
+		return LinkScopeSameHost
+	}
+	if u.Scheme == "" {
+		u.Scheme = baseURL.Scheme
+	}
+
+	switch {
+	case hostKey(u) == hostKey(baseURL):
+		return LinkScopeSameHost
+	case registrableDomain(normalizeHost(u.Hostname())) == registrableDomain(normalizeHost(baseURL.Hostname())):
+		return LinkScopeSameSite
+	case isInternalHost(normalizeHost(u.Hostname()), internalHosts):
+		return LinkScopeSameSite
+	}
+	return LinkScopeExternal
+}
+
+// hostKey returns the lower case host of u with the port unless it is the default port of the scheme
+func hostKey(u *url.URL) string {
+	host := normalizeHost(u.Hostname())
+	if port := u.Port(); port != "" && port != defaultPorts[strings.ToLower(u.Scheme)] {
+		return net.JoinHostPort(host, port)
+	}
+	return host
+}
+
+// normalizeHost lower cases host and drops the trailing dot of a fully qualified name
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+// isInternalHost reports whether host is one of internalHosts or a subdomain of one
+func isInternalHost(host string, internalHosts []string) bool {
+	for _, internal := range internalHosts {
+		internal = normalizeHost(strings.TrimPrefix(strings.TrimSpace(internal), "*."))
+		if internal != "" && (host == internal || strings.HasSuffix(host, "."+internal)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package analyzer
+
+import (
+	"net/url"
+	"testing"
+)
+
+// Table-driven tests for linkScope
+func TestLinkScope(t *testing.T) {
+	baseURL, _ := url.Parse("https://www.example.co.uk/shop/")
+	internalHosts := []string{"cdn.partner.net", "*.example-static.com"}
+
+	tests := []struct {
+		name     string
+		link     string
+		base     string
+		expected string
+	}{
+		{name: "Relative", link: "cart", expected: LinkScopeSameHost},
+		{name: "Fragment", link: "#top", expected: LinkScopeSameHost},
+		{name: "SameHost", link: "https://www.example.co.uk/about", expected: LinkScopeSameHost},
+		{name: "SchemeRelative", link: "//www.example.co.uk/about", expected: LinkScopeSameHost},
+		{name: "DefaultPort", link: "https://www.example.co.uk:443/about", expected: LinkScopeSameHost},
+		{name: "CaseAndTrailingDot", link: "https://WWW.EXAMPLE.CO.UK./about", expected: LinkScopeSameHost},
+		{name: "OtherPort", link: "https://www.example.co.uk:8443/admin", expected: LinkScopeSameSite},
+		{name: "HTTPDefaultPort", link: "http://www.example.co.uk:80/", expected: LinkScopeSameHost},
+		{name: "BareDomain", link: "https://example.co.uk/", expected: LinkScopeSameSite},
+		{name: "Subdomain", link: "https://blog.example.co.uk/", expected: LinkScopeSameSite},
+		{name: "SharedPublicSuffix", link: "https://other.co.uk/", expected: LinkScopeExternal},
+		{name: "InternalHost", link: "https://cdn.partner.net/file.pdf", expected: LinkScopeSameSite},
+		{name: "InternalHostWildcard", link: "https://img.example-static.com/a.png", expected: LinkScopeSameSite},
+		{name: "OtherHostOfInternalDomain", link: "https://www.partner.net/", expected: LinkScopeExternal},
+		{name: "External", link: "https://www.google.com/", expected: LinkScopeExternal},
+		{name: "ExternalWithPath", link: "https://www.example.org/path", expected: LinkScopeExternal},
+		{name: "LookalikeDomain", link: "https://www.example.co.uk.evil.net/", expected: LinkScopeExternal},
+		{name: "Empty", link: "", expected: LinkScopeSameHost},
+		{name: "Invalid", link: ":invalid-link", expected: LinkScopeSameHost},
+		{name: "IPv4SameHost", link: "http://127.0.0.1:8080/about", base: "http://127.0.0.1:8080/", expected: LinkScopeSameHost},
+		{name: "IPv4OtherPort", link: "http://127.0.0.1:9090/", base: "http://127.0.0.1:8080/", expected: LinkScopeSameSite},
+		{name: "IPv4OtherAddress", link: "http://10.0.0.1/", base: "http://127.0.0.1:8080/", expected: LinkScopeExternal},
+		{name: "IPv6OtherAddress", link: "http://[::2]/", base: "http://[::1]/", expected: LinkScopeExternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := baseURL
+			if tt.base != "" {
+				base, _ = url.Parse(tt.base)
+			}
+			if got := linkScope(tt.link, base, internalHosts); got != tt.expected {
+				t.Errorf("linkScope(%q) = %v, want %v", tt.link, got, tt.expected)
+			}
+		})
+	}
+}
+
+// Table-driven tests for getLinkScopes
+func TestGetLinkScopes(t *testing.T) {
+	baseURL, _ := url.Parse("https://example.com")
+
+	tests := []struct {
+		name     string
+		html     string
+		expected LinkScopes
+	}{
+		{
+			name:     "NoLinks",
+			html:     `<html><head><title>Test</title></head><body></body></html>`,
+			expected: LinkScopes{},
+		},
+		{
+			name: "AllScopes",
+			html: `<html><body>
+				<a href="/about">About</a>
+				<a href="https://example.com/contact">Contact</a>
+				<a href="https://www.example.com/">Home</a>
+				<a href="https://docs.example.com/">Docs</a>
+				<a href="https://github.com/example">GitHub</a>
+				<a>No href</a>
+			</body></html>`,
+			expected: LinkScopes{SameHost: 2, SameSite: 2, External: 1},
+		},
+		{
+			name: "NoExternalLinks",
+			html: `<!DOCTYPE html><html><head><title>Test</title></head><body>
+				<a href="/internal-link">Internal Link</a>
+				<a href="https://example.com/another-internal-link">Another Internal Link</a>
+			</body></html>`,
+			expected: LinkScopes{SameHost: 2},
+		},
+		{
+			name: "NoInternalLinks",
+			html: `<html><head><title>Test</title></head><body>
+				<h1>Example Domain</h1>
+				<p><a href="https://www.iana.org/domains/example">More information...</a></p>
+				<a href="https://www.google.com">Google</a>
+				<a href="https://www.example.org">Example</a>
+			</body></html>`,
+			expected: LinkScopes{External: 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parseHTML(tt.html)
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+			if got := getLinkScopes(doc, baseURL, nil); got != tt.expected {
+				t.Errorf("getLinkScopes() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package analyzer
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Types of mixed content
+const (
+	// MixedContentActive can change the page, such as scripts, stylesheets and frames, or submits data
+	MixedContentActive = "active"
+	// MixedContentPassive only displays, such as images and media
+	MixedContentPassive = "passive"
+)
+
+// Severities of mixed content
+const (
+	// SeverityHigh marks resources browsers block
+	SeverityHigh = "high"
+	// SeverityMedium marks forms browsers warn about before submitting
+	SeverityMedium = "medium"
+	// SeverityLow marks resources browsers upgrade to https or load with a warning
+	SeverityLow = "low"
+)
+
+// lazyLoadAttributes hold the URLs lazy loading libraries copy into src and srcset
+var lazyLoadAttributes = []string{"data-src", "data-srcset", "data-lazy-src", "data-lazy-srcset", "data-original", "data-bg", "data-background"}
+
+// activeElements load content that can change the page
+var activeElements = []string{"script", "iframe", "frame", "object", "embed"}
+
+// MixedContent lists the http resources referenced by an https page
+type MixedContent struct {
+	Active  int                `json:"active"`
+	Passive int                `json:"passive"`
+	Items   []MixedContentItem `json:"items"`
+}
+
+// MixedContentItem is a single http URL on an https page
+type MixedContentItem struct {
+	URL string `json:"url"`
+	// Element is a CSS-like path to the element referencing the URL
+	Element   string `json:"element"`
+	Attribute string `json:"attribute"`
+	Type      string `json:"type"`
+	Severity  string `json:"severity"`
+}
+
+// getMixedContent finds the http URLs an https page at baseURL loads resources from or submits to.
+// Pages that are not served over https have no mixed content.
+func getMixedContent(doc *html.Node, baseURL *url.URL) MixedContent {
+	mixed := MixedContent{Items: []MixedContentItem{}}
+	if baseURL.Scheme != "https" {
+		return mixed
+	}
+
+	add := func(n *html.Node, attribute, ref, contentType, severity string) {
+		u, err := url.Parse(strings.TrimSpace(ref))
+		if err != nil {
+			return
+		}
+		// relative URLs inherit https, so only absolute http URLs are mixed
+		if u = baseURL.ResolveReference(u); u.Scheme != "http" {
+			return
+		}
+		mixed.Items = append(mixed.Items, MixedContentItem{
+			URL:       u.String(),
+			Element:   elementPath(n),
+			Attribute: attribute,
+			Type:      contentType,
+			Severity:  severity,
+		})
+		if contentType == MixedContentActive {
+			mixed.Active++
+		} else {
+			mixed.Passive++
+		}
+	}
+	// check adds the URL of attribute, or every candidate of a srcset
+	check := func(n *html.Node, attribute, contentType, severity string) {
+		val, ok := getAttr(n, attribute)
+		if !ok {
+			return
+		}
+		if !strings.HasSuffix(attribute, "srcset") {
+			add(n, attribute, val, contentType, severity)
+			return
+		}
+		for _, c := range parseSrcset(val) {
+			add(n, attribute, c.url, contentType, severity)
+		}
+	}
+
+	var traverse func(*html.Node)
+	traverse = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			active := slices.Contains(activeElements, n.Data)
+			switch n.Data {
+			case "script", "iframe", "frame", "embed":
+				check(n, "src", MixedContentActive, SeverityHigh)
+			case "object":
+				check(n, "data", MixedContentActive, SeverityHigh)
+			case "link":
+				rel := strings.Fields(strings.ToLower(attrValue(n, "rel")))
+				as := strings.ToLower(attrValue(n, "as"))
+				switch {
+				case slices.Contains(rel, "stylesheet") || slices.Contains(rel, "modulepreload") ||
+					(slices.Contains(rel, "preload") && (as == "script" || as == "style")):
+					check(n, "href", MixedContentActive, SeverityHigh)
+				case slices.Contains(rel, "icon") || slices.Contains(rel, "preload"):
+					check(n, "href", MixedContentPassive, SeverityLow)
+				}
+			case "img", "source":
+				check(n, "src", MixedContentPassive, SeverityLow)
+				check(n, "srcset", MixedContentPassive, SeverityLow)
+			case "audio", "video", "track":
+				check(n, "src", MixedContentPassive, SeverityLow)
+				check(n, "poster", MixedContentPassive, SeverityLow)
+			case "input":
+				if strings.EqualFold(attrValue(n, "type"), "image") {
+					check(n, "src", MixedContentPassive, SeverityLow)
+				}
+				check(n, "formaction", MixedContentActive, SeverityMedium)
+			case "button":
+				check(n, "formaction", MixedContentActive, SeverityMedium)
+			case "form":
+				check(n, "action", MixedContentActive, SeverityMedium)
+			}
+
+			// lazy loaded frames and scripts become active content once the loader copies the URL
+			for _, attribute := range lazyLoadAttributes {
+				if active {
+					check(n, attribute, MixedContentActive, SeverityHigh)
+				} else {
+					check(n, attribute, MixedContentPassive, SeverityLow)
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			traverse(c)
+		}
+	}
+	traverse(doc)
+	return mixed
+}
@@ -0,0 +1,104 @@
+package analyzer
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// Table-driven tests for getMixedContent
+func TestGetMixedContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		baseURL  string
+		html     string
+		expected MixedContent
+	}{
+		{
+			name:     "HTTPPage",
+			baseURL:  "http://example.com/",
+			html:     `<html><body><script src="http://cdn.example.com/app.js"></script></body></html>`,
+			expected: MixedContent{Items: []MixedContentItem{}},
+		},
+		{
+			name:    "SecureAndRelativeURLs",
+			baseURL: "https://example.com/",
+			html: `<html><body>
+				<script src="/app.js"></script><img src="//cdn.example.com/a.png">
+				<a href="http://example.org/">Plain links are not mixed content</a>
+			</body></html>`,
+			expected: MixedContent{Items: []MixedContentItem{}},
+		},
+		{
+			name:    "ActiveContent",
+			baseURL: "https://example.com/",
+			html: `<html><head>
+				<link rel="stylesheet" href="http://cdn.example.com/site.css">
+				<script src="HTTP://cdn.example.com/app.js"></script>
+			</head><body>
+				<iframe id="video" src="http://video.example.com/embed"></iframe>
+				<object data="http://example.com/movie.swf"></object>
+			</body></html>`,
+			expected: MixedContent{
+				Active: 4,
+				Items: []MixedContentItem{
+					{URL: "http://cdn.example.com/site.css", Element: "html > head > link", Attribute: "href", Type: MixedContentActive, Severity: SeverityHigh},
+					{URL: "http://cdn.example.com/app.js", Element: "html > head > script", Attribute: "src", Type: MixedContentActive, Severity: SeverityHigh},
+					{URL: "http://video.example.com/embed", Element: "html > body > iframe#video", Attribute: "src", Type: MixedContentActive, Severity: SeverityHigh},
+					{URL: "http://example.com/movie.swf", Element: "html > body > object", Attribute: "data", Type: MixedContentActive, Severity: SeverityHigh},
+				},
+			},
+		},
+		{
+			name:    "PassiveContentAndForms",
+			baseURL: "https://example.com/",
+			html: `<html><body>
+				<img src="https://example.com/a.png" srcset="http://example.com/a-1x.png 1x, https://example.com/a-2x.png 2x">
+				<video poster="http://example.com/poster.jpg"><source src="http://example.com/movie.mp4"></video>
+				<form action="http://example.com/login"><button formaction="/signup">Sign up</button></form>
+			</body></html>`,
+			expected: MixedContent{
+				Active:  1,
+				Passive: 3,
+				Items: []MixedContentItem{
+					{URL: "http://example.com/a-1x.png", Element: "html > body > img", Attribute: "srcset", Type: MixedContentPassive, Severity: SeverityLow},
+					{URL: "http://example.com/poster.jpg", Element: "html > body > video", Attribute: "poster", Type: MixedContentPassive, Severity: SeverityLow},
+					{URL: "http://example.com/movie.mp4", Element: "html > body > video > source", Attribute: "src", Type: MixedContentPassive, Severity: SeverityLow},
+					{URL: "http://example.com/login", Element: "html > body > form", Attribute: "action", Type: MixedContentActive, Severity: SeverityMedium},
+				},
+			},
+		},
+		{
+			name:    "LazyLoadAttributes",
+			baseURL: "https://example.com/",
+			html: `<html><body>
+				<img class="lazy" data-src="http://example.com/a.png" data-srcset="http://example.com/b.png 2x">
+				<div data-bg="http://example.com/bg.jpg"></div>
+				<iframe data-src="http://video.example.com/embed"></iframe>
+			</body></html>`,
+			expected: MixedContent{
+				Active:  1,
+				Passive: 3,
+				Items: []MixedContentItem{
+					{URL: "http://example.com/a.png", Element: "html > body > img", Attribute: "data-src", Type: MixedContentPassive, Severity: SeverityLow},
+					{URL: "http://example.com/b.png", Element: "html > body > img", Attribute: "data-srcset", Type: MixedContentPassive, Severity: SeverityLow},
+					{URL: "http://example.com/bg.jpg", Element: "html > body > div", Attribute: "data-bg", Type: MixedContentPassive, Severity: SeverityLow},
+					{URL: "http://video.example.com/embed", Element: "html > body > iframe", Attribute: "data-src", Type: MixedContentActive, Severity: SeverityHigh},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseURL, _ := url.Parse(tt.baseURL)
+			doc, err := parseHTML(tt.html)
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+			if got := getMixedContent(doc, baseURL); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("getMixedContent() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
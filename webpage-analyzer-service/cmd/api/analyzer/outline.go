@@ -0,0 +1,103 @@
+package analyzer
+
+import (
+	"fmt"
+
+	"golang.org/x/net/html"
+)
+
+// Codes of the problems found in the heading structure
+const (
+	OutlineIssueMissingH1    = "missing_h1"
+	OutlineIssueMultipleH1   = "multiple_h1"
+	OutlineIssueSkippedLevel = "skipped_level"
+	OutlineIssueEmptyHeading = "empty_heading"
+)
+
+// Outline is the heading structure of the page
+type Outline struct {
+	// Headings lists every h1–h6 in document order
+	Headings []Heading      `json:"headings"`
+	Issues   []OutlineIssue `json:"issues"`
+}
+
+// Heading is a single h1–h6 element
+type Heading struct {
+	// Order is the 1-based position of the heading in the document
+	Order int    `json:"order"`
+	Level int    `json:"level"`
+	Text  string `json:"text"`
+}
+
+// OutlineIssue is a problem in the heading structure content editors should fix
+type OutlineIssue struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Heading is the order of the heading the issue is about, zero for the whole page
+	Heading int `json:"heading,omitempty"`
+}
+
+// headingLevels maps heading elements to their level
+var headingLevels = map[string]int{"h1": 1, "h2": 2, "h3": 3, "h4": 4, "h5": 5, "h6": 6}
+
+// getOutline returns the headings of the HTML document in order together with
+// the problems in their structure
+func getOutline(doc *html.Node) Outline {
+	outline := Outline{Headings: []Heading{}, Issues: []OutlineIssue{}}
+	var traverse func(*html.Node)
+	traverse = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			if level, ok := headingLevels[n.Data]; ok {
+				outline.Headings = append(outline.Headings, Heading{
+					Order: len(outline.Headings) + 1,
+					Level: level,
+					Text:  textContent(n),
+				})
+				// headings cannot be nested, so there is nothing more to find inside
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			traverse(c)
+		}
+	}
+	traverse(doc)
+
+	h1Count := 0
+	previousLevel := 0
+	for _, h := range outline.Headings {
+		if h.Level == 1 {
+			h1Count++
+			if h1Count == 2 {
+				outline.Issues = append(outline.Issues, OutlineIssue{
+					Code:    OutlineIssueMultipleH1,
+					Message: "the page has more than one h1",
+					Heading: h.Order,
+				})
+			}
+		}
+		if previousLevel > 0 && h.Level > previousLevel+1 {
+			outline.Issues = append(outline.Issues, OutlineIssue{
+				Code:    OutlineIssueSkippedLevel,
+				Message: fmt.Sprintf("h%d follows h%d, skipping h%d", h.Level, previousLevel, previousLevel+1),
+				Heading: h.Order,
+			})
+		}
+		if h.Text == "" {
+			outline.Issues = append(outline.Issues, OutlineIssue{
+				Code:    OutlineIssueEmptyHeading,
+				Message: fmt.Sprintf("h%d has no text", h.Level),
+				Heading: h.Order,
+			})
+		}
+		previousLevel = h.Level
+	}
+	if h1Count == 0 {
+		outline.Issues = append(outline.Issues, OutlineIssue{
+			Code:    OutlineIssueMissingH1,
+			Message: "the page has no h1",
+		})
+	}
+
+	return outline
+}
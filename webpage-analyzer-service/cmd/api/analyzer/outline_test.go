@@ -0,0 +1,84 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Table-driven tests for getOutline
+func TestGetOutline(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		expected Outline
+	}{
+		{
+			name: "WellStructured",
+			html: `<html><body><h1>Title</h1><h2>Section  <em>one</em></h2><h3>Detail</h3><h2>Section two</h2></body></html>`,
+			expected: Outline{
+				Headings: []Heading{
+					{Order: 1, Level: 1, Text: "Title"},
+					{Order: 2, Level: 2, Text: "Section one"},
+					{Order: 3, Level: 3, Text: "Detail"},
+					{Order: 4, Level: 2, Text: "Section two"},
+				},
+				Issues: []OutlineIssue{},
+			},
+		},
+		{
+			name: "NoHeadings",
+			html: `<html><body><p>Text</p></body></html>`,
+			expected: Outline{
+				Headings: []Heading{},
+				Issues:   []OutlineIssue{{Code: OutlineIssueMissingH1, Message: "the page has no h1"}},
+			},
+		},
+		{
+			name: "MultipleH1",
+			html: `<html><body><h1>One</h1><h1>Two</h1><h1>Three</h1></body></html>`,
+			expected: Outline{
+				Headings: []Heading{
+					{Order: 1, Level: 1, Text: "One"},
+					{Order: 2, Level: 1, Text: "Two"},
+					{Order: 3, Level: 1, Text: "Three"},
+				},
+				Issues: []OutlineIssue{{Code: OutlineIssueMultipleH1, Message: "the page has more than one h1", Heading: 2}},
+			},
+		},
+		{
+			name: "SkippedLevel",
+			html: `<html><body><h1>Title</h1><h2>Section</h2><h4>Detail</h4></body></html>`,
+			expected: Outline{
+				Headings: []Heading{
+					{Order: 1, Level: 1, Text: "Title"},
+					{Order: 2, Level: 2, Text: "Section"},
+					{Order: 3, Level: 4, Text: "Detail"},
+				},
+				Issues: []OutlineIssue{{Code: OutlineIssueSkippedLevel, Message: "h4 follows h2, skipping h3", Heading: 3}},
+			},
+		},
+		{
+			name: "EmptyHeading",
+			html: `<html><body><h1><img src="logo.png" alt="Company"></h1><h2>  </h2></body></html>`,
+			expected: Outline{
+				Headings: []Heading{
+					{Order: 1, Level: 1, Text: "Company"},
+					{Order: 2, Level: 2, Text: ""},
+				},
+				Issues: []OutlineIssue{{Code: OutlineIssueEmptyHeading, Message: "h2 has no text", Heading: 2}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parseHTML(tt.html)
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+			if got := getOutline(doc); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("getOutline() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
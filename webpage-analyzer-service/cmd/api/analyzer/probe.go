@@ -0,0 +1,156 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// Probe is what a request for a resource referenced by the page returned
+type Probe struct {
+	// Status is the HTTP status code, zero when the request failed
+	Status int `json:"status"`
+	// ContentType is the media type of the response without parameters
+	ContentType string `json:"contentType,omitempty"`
+	// Bytes is the size of the response body as transferred, zero when unknown
+	Bytes int64 `json:"bytes"`
+}
+
+// OK reports whether the resource was served successfully
+func (p Probe) OK() bool {
+	return p.Status >= 200 && p.Status < 300
+}
+
+// errUnchecked reports a check that was not sent, or was cut short, because the analysis ended
+var errUnchecked = errors.New("check left out because the analysis ended")
+
+// cancelOnClose releases the context of a check once its body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// sendCheck requests link once the rate limit of its host allows it. The LinkCheckTimeout
+// only starts then, so waiting for our own limiter never makes a link look broken.
+// It fails with errUnchecked when ctx ends before the check is done.
+func (a *Analyzer) sendCheck(ctx context.Context, method, link string) (*http.Response, error) {
+	if err := a.hosts.wait(ctx, link, -1); err != nil {
+		return nil, errUnchecked
+	}
+
+	checkCtx, cancel := withTimeout(ctx, a.opts.LinkCheckTimeout)
+	req, err := a.newRequest(checkCtx, method, link)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp, err := a.client.Do(req)
+	if err != nil {
+		cancel()
+		if ctx.Err() != nil {
+			return nil, errUnchecked
+		}
+		return nil, err
+	}
+	resp.Body = cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// probeResource requests link with HEAD, falling back to GET when the server does not
+// support HEAD or does not report the size. Bodies are read up to MaxDocumentSize bytes,
+// as long as the bytes of the link check budget last. It reports false when link was not
+// probed because the fallback was needed but the link check budget was spent, or the
+// analysis ended first.
+func (a *Analyzer) probeResource(ctx context.Context, link string) (Probe, bool) {
+	resp, err := a.sendCheck(ctx, http.MethodHead, link)
+	if errors.Is(err, errUnchecked) {
+		return Probe{}, false
+	}
+	if err == nil && resp.StatusCode != http.StatusMethodNotAllowed && resp.StatusCode != http.StatusNotImplemented &&
+		(resp.ContentLength >= 0 || resp.StatusCode != http.StatusOK) {
+		resp.Body.Close()
+		return newProbe(resp, resp.ContentLength), true
+	}
+	head, headOK := Probe{}, false
+	if err == nil {
+		resp.Body.Close()
+		// a successful HEAD without a size still tells that the resource exists
+		head, headOK = newProbe(resp, resp.ContentLength), resp.StatusCode == http.StatusOK
+	}
+
+	// the GET is a request of its own, so it needs the link check budget as well
+	budget := a.budgetFrom(ctx)
+	if !budget.hasBytes() || !budget.take() {
+		return head, headOK
+	}
+	resp, err = a.sendCheck(ctx, http.MethodGet, link)
+	if errors.Is(err, errUnchecked) {
+		return head, headOK
+	}
+	if err != nil {
+		return Probe{}, true
+	}
+	defer resp.Body.Close()
+	return newProbe(resp, budget.discard(resp.Body, a.opts.MaxDocumentSize)), true
+}
+
+// newProbe describes resp, whose body is size bytes long
+func newProbe(resp *http.Response, size int64) Probe {
+	p := Probe{Status: resp.StatusCode, Bytes: max(size, 0)}
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil {
+		p.ContentType = mediaType
+	}
+	return p
+}
+
+// probeResources probes every distinct http and https URL of links as long as the
+// link check budget of ctx lasts
+func (a *Analyzer) probeResources(ctx context.Context, links []string) map[string]Probe {
+	var distinct []string
+	seen := make(map[string]bool)
+	for _, link := range links {
+		u, err := url.Parse(link)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || seen[link] {
+			continue
+		}
+		seen[link] = true
+		distinct = append(distinct, link)
+	}
+
+	budget := a.budgetFrom(ctx)
+	probes := make(map[string]Probe, len(distinct))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, link := range distinct {
+		if !budget.take() {
+			budget.skip()
+			continue
+		}
+		wg.Add(1)
+		budget.acquire()
+		go func(link string) {
+			defer wg.Done()
+			defer budget.release()
+			p, ok := a.probeResource(ctx, link)
+			if !ok {
+				budget.skip()
+				return
+			}
+			mu.Lock()
+			probes[link] = p
+			mu.Unlock()
+		}(link)
+	}
+	wg.Wait()
+	return probes
+}
@@ -0,0 +1,85 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestProbeResourcesByteBudget(t *testing.T) {
+	body := strings.Repeat("x", 100<<10)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// without HEAD support every probe falls back to a GET of the whole body
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	defer ts.Close()
+
+	opts := DefaultOptions()
+	opts.MaxLinkChecks = 1
+	opts.MaxProbeBytes = 150 << 10
+	a := New(opts)
+	ctx, budget := a.withLinkCheckBudget(context.Background())
+
+	links := []string{ts.URL + "/a", ts.URL + "/b", ts.URL + "/c"}
+	probes := a.probeResources(ctx, links)
+
+	var total int64
+	for _, p := range probes {
+		total += p.Bytes
+	}
+	// a probe may read one buffer past the budget before it notices
+	if total > opts.MaxProbeBytes+32<<10 {
+		t.Errorf("probes downloaded %d bytes, want at most %d", total, opts.MaxProbeBytes+32<<10)
+	}
+	if len(probes) != 2 {
+		t.Errorf("probed %d URLs, want 2", len(probes))
+	}
+	if got := budget.numSkipped(); got != 1 {
+		t.Errorf("skipped %d probes, want 1", got)
+	}
+}
+
+func TestAnalyzeURLSharedLinkCheckConcurrency(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int64
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			var page strings.Builder
+			for i := 0; i < 4; i++ {
+				fmt.Fprintf(&page, `<a href="%[1]s/page/%[2]d">Page</a><img src="%[1]s/%[2]d.png" alt=""><script src="%[1]s/%[2]d.js"></script>`, ts.URL, i)
+			}
+			w.Write([]byte(`<!DOCTYPE html><html><head><title>Checks</title></head><body>` + page.String() + `</body></html>`))
+			return
+		}
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Content-Length", "0")
+	}))
+	defer ts.Close()
+
+	// links, images and scripts are checked by separate goroutines sharing one limit
+	opts := DefaultOptions()
+	opts.MaxLinkChecks = 2
+	if _, err := New(opts).AnalyzeURL(context.Background(), ts.URL); err != nil {
+		t.Fatalf("AnalyzeURL() error = %v", err)
+	}
+	if got := maxInFlight.Load(); got > 2 {
+		t.Errorf("checks in flight = %v, want at most 2", got)
+	}
+}
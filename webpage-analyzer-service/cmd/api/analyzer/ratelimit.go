@@ -0,0 +1,71 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"webpage-analyzer/internal/ratelimit"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimitError is returned when the analyzed page's host has been requested too often
+type RateLimitError struct {
+	Host string
+	// RetryAfter is how long until the host may be requested again
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("too many requests to %s, retry in %s", e.Host, e.RetryAfter.Round(time.Second))
+}
+
+// hostLimiters keeps a token bucket for every host the analyzer talks to
+type hostLimiters struct {
+	*ratelimit.Limiters
+}
+
+func newHostLimiters(limit rate.Limit, burst int) *hostLimiters {
+	return &hostLimiters{ratelimit.New(limit, burst)}
+}
+
+// wait blocks until a request to the host of urlStr is allowed. It fails with a
+// RateLimitError instead when that would take longer than maxWait; a negative
+// maxWait waits as long as ctx allows.
+func (h *hostLimiters) wait(ctx context.Context, urlStr string, maxWait time.Duration) error {
+	if h == nil {
+		return nil
+	}
+
+	u, err := url.Parse(urlStr)
+	if err != nil || u.Host == "" {
+		return nil
+	}
+	host := strings.ToLower(u.Hostname())
+
+	reservation := h.Get(host).Reserve()
+	if !reservation.OK() {
+		return &RateLimitError{Host: host, RetryAfter: time.Second}
+	}
+
+	delay := reservation.Delay()
+	if delay == 0 {
+		return nil
+	}
+	if maxWait >= 0 && delay > maxWait {
+		reservation.Cancel()
+		return &RateLimitError{Host: host, RetryAfter: delay}
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		reservation.Cancel()
+		return ctx.Err()
+	}
+}
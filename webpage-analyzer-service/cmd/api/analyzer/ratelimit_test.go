@@ -0,0 +1,85 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAnalyzeURLHostRateLimit(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<!DOCTYPE html><html><head><title>Test</title></head><body></body></html>`))
+	}))
+	defer ts.Close()
+
+	// one request per minute, and the page fetch must not wait at all
+	opts := DefaultOptions()
+	opts.HostRate = 1.0 / 60
+	opts.HostBurst = 1
+	opts.HostMaxWait = 0
+	a := New(opts)
+
+	if _, err := a.AnalyzeURL(context.Background(), ts.URL); err != nil {
+		t.Fatalf("first AnalyzeURL() error = %v", err)
+	}
+
+	_, err := a.AnalyzeURL(context.Background(), ts.URL)
+	var rateLimitErr *RateLimitError
+	if !errors.As(err, &rateLimitErr) {
+		t.Fatalf("second AnalyzeURL() error = %v, want a RateLimitError", err)
+	}
+	if rateLimitErr.RetryAfter <= 0 || rateLimitErr.RetryAfter > time.Minute {
+		t.Errorf("RetryAfter = %v, want up to a minute", rateLimitErr.RetryAfter)
+	}
+}
+
+// Table-driven tests for hostLimiters.wait
+func TestHostLimitersWait(t *testing.T) {
+	tests := []struct {
+		name     string
+		urls     []string
+		maxWait  time.Duration
+		expected []bool
+	}{
+		{
+			name:     "BurstAllowed",
+			urls:     []string{"https://a.example/1", "https://a.example/2"},
+			expected: []bool{true, true},
+		},
+		{
+			name:     "OverBurstRejected",
+			urls:     []string{"https://a.example/1", "https://a.example/2", "https://a.example/3"},
+			expected: []bool{true, true, false},
+		},
+		{
+			name:     "HostsLimitedSeparately",
+			urls:     []string{"https://a.example/1", "https://a.example/2", "https://b.example/1"},
+			expected: []bool{true, true, true},
+		},
+		{
+			name:     "HostCaseIgnored",
+			urls:     []string{"https://a.example/1", "https://A.EXAMPLE/2", "https://a.example/3"},
+			expected: []bool{true, true, false},
+		},
+		{
+			name:     "RelativeLinksIgnored",
+			urls:     []string{"/1", "/2", "/3"},
+			expected: []bool{true, true, true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiters := newHostLimiters(1.0/60, 2)
+			for i, u := range tt.urls {
+				err := limiters.wait(context.Background(), u, tt.maxWait)
+				if got := err == nil; got != tt.expected[i] {
+					t.Errorf("wait(%q) error = %v, want allowed %v", u, err, tt.expected[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package analyzer
+
+import (
+	"context"
+	"net/url"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Kinds of resources a page loads
+const (
+	ResourceScript       = "script"
+	ResourceModuleScript = "module_script"
+	ResourceInlineScript = "inline_script"
+	ResourceStylesheet   = "stylesheet"
+	// ResourceHint is a <link> asking the browser to preload, prefetch or connect ahead of time
+	ResourceHint = "hint"
+)
+
+// resourceHints are the rel values of resource hint links
+var resourceHints = []string{"preload", "modulepreload", "prefetch", "preconnect", "dns-prefetch", "prerender"}
+
+// ResourceInventory lists the scripts, stylesheets and resource hints of the page
+type ResourceInventory struct {
+	Resources []Resource `json:"resources"`
+	// RenderBlocking counts the scripts and stylesheets in the <head> that hold up rendering
+	RenderBlocking int `json:"renderBlocking"`
+	// ThirdParty counts the resources loaded from other sites
+	ThirdParty int `json:"thirdParty"`
+	// TotalBytes adds up the sizes of the scripts and stylesheets that are known
+	TotalBytes int64 `json:"totalBytes"`
+}
+
+// Resource is a script, stylesheet or resource hint
+type Resource struct {
+	Kind string `json:"kind"`
+	// URL is resolved against the page, empty for inline scripts
+	URL string `json:"url,omitempty"`
+	// Element is a CSS-like path to the element
+	Element string `json:"element"`
+	// Rel is the resource hint, e.g. preload, and As the destination of a preload
+	Rel        string `json:"rel,omitempty"`
+	As         string `json:"as,omitempty"`
+	ThirdParty bool   `json:"thirdParty"`
+	Async      bool   `json:"async"`
+	Defer      bool   `json:"defer"`
+	// RenderBlocking reports scripts and stylesheets in the <head> the browser waits for before rendering
+	RenderBlocking bool `json:"renderBlocking"`
+	// Bytes is the size of an inline script, or of an external resource once probed
+	Bytes int64 `json:"bytes"`
+	// Probe is nil for inline scripts and resources that were not requested
+	Probe *Probe `json:"probe,omitempty"`
+}
+
+// getResources returns the scripts, stylesheets and resource hints of the document with
+// their URLs resolved against baseURL. External resources are not probed yet.
+func getResources(doc *html.Node, baseURL *url.URL) ResourceInventory {
+	inventory := ResourceInventory{Resources: []Resource{}}
+	var traverse func(n *html.Node, inHead bool)
+	traverse = func(n *html.Node, inHead bool) {
+		if n.Type == html.ElementNode {
+			var resource *Resource
+			switch n.Data {
+			case "head":
+				inHead = true
+			case "script":
+				resource = describeScript(n, baseURL, inHead)
+			case "link":
+				resource = describeLink(n, baseURL, inHead)
+			}
+			if resource != nil {
+				inventory.Resources = append(inventory.Resources, *resource)
+				if resource.RenderBlocking {
+					inventory.RenderBlocking++
+				}
+				if resource.ThirdParty {
+					inventory.ThirdParty++
+				}
+				inventory.TotalBytes += resource.Bytes
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			traverse(c, inHead)
+		}
+	}
+	traverse(doc, false)
+	return inventory
+}
+
+// describeScript describes a <script>, or returns nil for data blocks such as JSON-LD
+func describeScript(n *html.Node, baseURL *url.URL, inHead bool) *Resource {
+	scriptType := strings.ToLower(strings.TrimSpace(attrValue(n, "type")))
+	module := scriptType == "module"
+	if !module && scriptType != "" && !strings.Contains(scriptType, "javascript") && !strings.Contains(scriptType, "ecmascript") {
+		return nil
+	}
+
+	src, hasSrc := getAttr(n, "src")
+	if !hasSrc {
+		return &Resource{Kind: ResourceInlineScript, Element: elementPath(n), Bytes: int64(len(rawText(n)))}
+	}
+
+	r := &Resource{
+		Kind:    ResourceScript,
+		URL:     resolveURL(baseURL, src),
+		Element: elementPath(n),
+		Async:   hasAttr(n, "async"),
+		Defer:   hasAttr(n, "defer"),
+	}
+	if module {
+		r.Kind = ResourceModuleScript
+	}
+	r.ThirdParty = isThirdParty(r.URL, baseURL)
+	// module scripts are deferred unless they are async
+	r.RenderBlocking = inHead && !module && !r.Async && !r.Defer
+	return r
+}
+
+// describeLink describes a stylesheet or resource hint <link>, or returns nil for other links
+func describeLink(n *html.Node, baseURL *url.URL, inHead bool) *Resource {
+	href, hasHref := getAttr(n, "href")
+	if !hasHref {
+		return nil
+	}
+	rel := strings.Fields(strings.ToLower(attrValue(n, "rel")))
+
+	r := &Resource{URL: resolveURL(baseURL, href), Element: elementPath(n)}
+	switch {
+	case slices.Contains(rel, "stylesheet"):
+		r.Kind = ResourceStylesheet
+		// print styles and disabled alternate stylesheets do not hold up rendering
+		media := strings.ToLower(strings.TrimSpace(attrValue(n, "media")))
+		r.RenderBlocking = inHead && media != "print" && !slices.Contains(rel, "alternate") && !hasAttr(n, "disabled")
+	default:
+		for _, hint := range resourceHints {
+			if slices.Contains(rel, hint) {
+				r.Kind = ResourceHint
+				r.Rel = hint
+				r.As = strings.ToLower(attrValue(n, "as"))
+				break
+			}
+		}
+		if r.Kind == "" {
+			return nil
+		}
+	}
+	r.ThirdParty = isThirdParty(r.URL, baseURL)
+	return r
+}
+
+// checkResources probes the external scripts, stylesheets and preloaded or prefetched
+// resources of the inventory and adds up their sizes
+func (a *Analyzer) checkResources(ctx context.Context, inventory *ResourceInventory) {
+	var links []string
+	for _, r := range inventory.Resources {
+		if isProbedResource(r) {
+			links = append(links, r.URL)
+		}
+	}
+	probes := a.probeResources(ctx, links)
+
+	for i := range inventory.Resources {
+		r := &inventory.Resources[i]
+		p, ok := probes[r.URL]
+		if !isProbedResource(*r) || !ok {
+			continue
+		}
+		r.Probe = &p
+		r.Bytes = p.Bytes
+		// hints fetch the resource a script or stylesheet loads again, so they are not counted twice
+		if r.Kind != ResourceHint {
+			inventory.TotalBytes += p.Bytes
+		}
+	}
+}
+
+// isProbedResource reports whether r is fetched from its URL, as opposed to inline
+// scripts and hints that only open a connection
+func isProbedResource(r Resource) bool {
+	switch r.Kind {
+	case ResourceScript, ResourceModuleScript, ResourceStylesheet:
+		return true
+	case ResourceHint:
+		return r.Rel == "preload" || r.Rel == "modulepreload" || r.Rel == "prefetch"
+	}
+	return false
+}
+
+// isThirdParty reports whether link is loaded from another site than the page at baseURL
+func isThirdParty(link string, baseURL *url.URL) bool {
+	u, err := url.Parse(link)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	return !isSameSiteHost(u.Hostname(), baseURL.Hostname())
+}
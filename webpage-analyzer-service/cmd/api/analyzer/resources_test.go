@@ -0,0 +1,125 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// Table-driven tests for getResources
+func TestGetResources(t *testing.T) {
+	baseURL, _ := url.Parse("https://www.example.com/app/")
+
+	tests := []struct {
+		name     string
+		html     string
+		expected ResourceInventory
+	}{
+		{
+			name:     "NoResources",
+			html:     `<html><body><p>Text</p></body></html>`,
+			expected: ResourceInventory{Resources: []Resource{}},
+		},
+		{
+			name: "RenderBlockingHead",
+			html: `<html><head>
+				<link rel="stylesheet" href="/main.css">
+				<link rel="stylesheet" href="/print.css" media="print">
+				<script src="app.js"></script>
+				<script src="https://cdn.example.net/lib.js" async></script>
+				<script src="/deferred.js" defer></script>
+				<script type="module" src="/main.mjs"></script>
+			</head><body></body></html>`,
+			expected: ResourceInventory{
+				Resources: []Resource{
+					{Kind: ResourceStylesheet, URL: "https://www.example.com/main.css", Element: "html > head > link:nth-of-type(1)", RenderBlocking: true},
+					{Kind: ResourceStylesheet, URL: "https://www.example.com/print.css", Element: "html > head > link:nth-of-type(2)"},
+					{Kind: ResourceScript, URL: "https://www.example.com/app/app.js", Element: "html > head > script:nth-of-type(1)", RenderBlocking: true},
+					{Kind: ResourceScript, URL: "https://cdn.example.net/lib.js", Element: "html > head > script:nth-of-type(2)", ThirdParty: true, Async: true},
+					{Kind: ResourceScript, URL: "https://www.example.com/deferred.js", Element: "html > head > script:nth-of-type(3)", Defer: true},
+					{Kind: ResourceModuleScript, URL: "https://www.example.com/main.mjs", Element: "html > head > script:nth-of-type(4)"},
+				},
+				RenderBlocking: 2,
+				ThirdParty:     1,
+			},
+		},
+		{
+			name: "InlineScriptsAndHints",
+			html: `<html><head>
+				<link rel="preconnect" href="https://fonts.example.org">
+				<link rel="preload" href="/font.woff2" as="font">
+				<link rel="icon" href="/favicon.ico">
+				<script type="application/ld+json">{"@type": "Organization"}</script>
+			</head><body>
+				<script>console.log(1)</script>
+				<script src="/late.js"></script>
+			</body></html>`,
+			expected: ResourceInventory{
+				Resources: []Resource{
+					{Kind: ResourceHint, URL: "https://fonts.example.org", Element: "html > head > link:nth-of-type(1)", Rel: "preconnect", ThirdParty: true},
+					{Kind: ResourceHint, URL: "https://www.example.com/font.woff2", Element: "html > head > link:nth-of-type(2)", Rel: "preload", As: "font"},
+					{Kind: ResourceInlineScript, Element: "html > body > script:nth-of-type(1)", Bytes: 14},
+					{Kind: ResourceScript, URL: "https://www.example.com/late.js", Element: "html > body > script:nth-of-type(2)"},
+				},
+				ThirdParty: 1,
+				TotalBytes: 14,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parseHTML(tt.html)
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+			if got := getResources(doc, baseURL); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("getResources() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCheckResources(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/app.js":
+			w.Header().Set("Content-Type", "text/javascript")
+			w.Write(make([]byte, 2048))
+		case "/main.css":
+			w.Header().Set("Content-Type", "text/css")
+			w.Write(make([]byte, 512))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	inventory := ResourceInventory{
+		Resources: []Resource{
+			{Kind: ResourceScript, URL: ts.URL + "/app.js"},
+			{Kind: ResourceStylesheet, URL: ts.URL + "/main.css"},
+			{Kind: ResourceHint, Rel: "preload", URL: ts.URL + "/app.js"},
+			{Kind: ResourceHint, Rel: "preconnect", URL: ts.URL},
+			{Kind: ResourceInlineScript, Bytes: 10},
+		},
+		TotalBytes: 10,
+	}
+	New(DefaultOptions()).checkResources(context.Background(), &inventory)
+
+	if inventory.TotalBytes != 2048+512+10 {
+		t.Errorf("TotalBytes = %d, want %d", inventory.TotalBytes, 2048+512+10)
+	}
+	if p := inventory.Resources[0].Probe; p == nil || *p != (Probe{Status: http.StatusOK, ContentType: "text/javascript", Bytes: 2048}) {
+		t.Errorf("Probe = %+v, want 200 text/javascript of 2048 bytes", p)
+	}
+	if inventory.Resources[2].Bytes != 2048 {
+		t.Errorf("preload Bytes = %d, want 2048", inventory.Resources[2].Bytes)
+	}
+	if p := inventory.Resources[3].Probe; p != nil {
+		t.Errorf("preconnect hint was probed: %+v", p)
+	}
+}
@@ -0,0 +1,292 @@
+package analyzer
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Grades of security headers
+const (
+	GradeMissing = "missing"
+	GradeWeak    = "weak"
+	GradeGood    = "good"
+)
+
+// minHSTSMaxAge is the shortest HSTS max-age considered good, 180 days in seconds
+const minHSTSMaxAge = 180 * 24 * 60 * 60
+
+// SecurityHeaders grades the HTTP security headers the page was served with
+type SecurityHeaders struct {
+	Headers []SecurityHeader `json:"headers"`
+	CSP     CSPInfo          `json:"csp"`
+}
+
+// SecurityHeader is the grade of a single header
+type SecurityHeader struct {
+	Name string `json:"name"`
+	// Value is the header as received, empty when it is missing
+	Value   string `json:"value,omitempty"`
+	Grade   string `json:"grade"`
+	Message string `json:"message"`
+}
+
+// CSPInfo is the parsed Content-Security-Policy of the page
+type CSPInfo struct {
+	// ReportOnly reports a policy only sent as Content-Security-Policy-Report-Only, which is not enforced
+	ReportOnly bool `json:"reportOnly"`
+	// Directives maps the directives of the first policy to their sources
+	Directives map[string][]string `json:"directives"`
+	Warnings   []string            `json:"warnings"`
+}
+
+// gradeFunc records the grade of a header
+type gradeFunc func(name, grade, format string, args ...any)
+
+// goodReferrerPolicies do not leak the path of the page to other origins
+var goodReferrerPolicies = []string{"no-referrer", "same-origin", "strict-origin", "strict-origin-when-cross-origin"}
+
+// referrerPolicies are all valid Referrer-Policy tokens
+var referrerPolicies = append([]string{"no-referrer-when-downgrade", "origin", "origin-when-cross-origin", "unsafe-url"}, goodReferrerPolicies...)
+
+// getSecurityHeaders grades the security headers of a page served from baseURL
+func getSecurityHeaders(header http.Header, baseURL *url.URL) SecurityHeaders {
+	sh := SecurityHeaders{Headers: []SecurityHeader{}}
+	grade := func(name, grade, format string, args ...any) {
+		sh.Headers = append(sh.Headers, SecurityHeader{
+			Name:    name,
+			Value:   strings.Join(header.Values(name), ", "),
+			Grade:   grade,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	gradeHSTS(header.Get("Strict-Transport-Security"), baseURL.Scheme == "https", grade)
+
+	sh.CSP = parseCSPHeaders(header)
+	switch {
+	case len(sh.CSP.Directives) == 0:
+		grade("Content-Security-Policy", GradeMissing, "the page has no Content-Security-Policy")
+	case sh.CSP.ReportOnly:
+		grade("Content-Security-Policy", GradeWeak, "the policy is only reported, not enforced")
+	case len(sh.CSP.Warnings) > 0:
+		grade("Content-Security-Policy", GradeWeak, "the policy has %d warnings", len(sh.CSP.Warnings))
+	default:
+		grade("Content-Security-Policy", GradeGood, "the policy restricts where scripts are loaded from")
+	}
+
+	frameAncestors, hasFrameAncestors := sh.CSP.Directives["frame-ancestors"]
+	if sh.CSP.ReportOnly {
+		hasFrameAncestors = false
+	}
+	switch xfo := strings.ToUpper(strings.TrimSpace(header.Get("X-Frame-Options"))); {
+	case hasFrameAncestors && !slices.Contains(frameAncestors, "*"):
+		grade("X-Frame-Options", GradeGood, "framing is restricted by the CSP frame-ancestors directive")
+	case xfo == "DENY":
+		grade("X-Frame-Options", GradeGood, "no site may frame the page")
+	case xfo == "SAMEORIGIN":
+		grade("X-Frame-Options", GradeGood, "only the same origin may frame the page")
+	case xfo == "":
+		grade("X-Frame-Options", GradeMissing, "any site may frame the page, set X-Frame-Options or CSP frame-ancestors")
+	default:
+		grade("X-Frame-Options", GradeWeak, "%q is not supported by browsers, use DENY, SAMEORIGIN or CSP frame-ancestors", header.Get("X-Frame-Options"))
+	}
+
+	switch xcto := strings.ToLower(strings.TrimSpace(header.Get("X-Content-Type-Options"))); xcto {
+	case "nosniff":
+		grade("X-Content-Type-Options", GradeGood, "browsers do not guess content types")
+	case "":
+		grade("X-Content-Type-Options", GradeMissing, "browsers may guess content types, set X-Content-Type-Options: nosniff")
+	default:
+		grade("X-Content-Type-Options", GradeWeak, "the only valid value is nosniff")
+	}
+
+	gradeReferrerPolicy(header.Values("Referrer-Policy"), grade)
+
+	switch pp := header.Get("Permissions-Policy"); {
+	case pp == "":
+		grade("Permissions-Policy", GradeMissing, "the page does not restrict browser features")
+	case strings.Contains(strings.ReplaceAll(pp, " ", ""), "=*"):
+		grade("Permissions-Policy", GradeWeak, "the policy allows some features for every origin")
+	default:
+		grade("Permissions-Policy", GradeGood, "the page restricts browser features")
+	}
+
+	switch coop := strings.ToLower(strings.TrimSpace(header.Get("Cross-Origin-Opener-Policy"))); coop {
+	case "same-origin":
+		grade("Cross-Origin-Opener-Policy", GradeGood, "the page is isolated from cross-origin windows")
+	case "":
+		grade("Cross-Origin-Opener-Policy", GradeMissing, "cross-origin windows opened by or opening the page can reference it")
+	default:
+		grade("Cross-Origin-Opener-Policy", GradeWeak, "%s does not isolate the page from every cross-origin window", coop)
+	}
+
+	switch coep := strings.ToLower(strings.TrimSpace(header.Get("Cross-Origin-Embedder-Policy"))); coep {
+	case "require-corp", "credentialless":
+		grade("Cross-Origin-Embedder-Policy", GradeGood, "cross-origin resources must opt in to being loaded")
+	case "":
+		grade("Cross-Origin-Embedder-Policy", GradeMissing, "the page may load cross-origin resources that did not opt in")
+	default:
+		grade("Cross-Origin-Embedder-Policy", GradeWeak, "%s lets the page load cross-origin resources that did not opt in", coep)
+	}
+
+	return sh
+}
+
+// gradeHSTS grades a Strict-Transport-Security header, which browsers only honor over https
+func gradeHSTS(value string, https bool, grade gradeFunc) {
+	const name = "Strict-Transport-Security"
+	if !https {
+		grade(name, GradeMissing, "the page is not served over https, so browsers ignore HSTS")
+		return
+	}
+	if value == "" {
+		grade(name, GradeMissing, "browsers may connect over plain http before being redirected")
+		return
+	}
+
+	maxAge := -1
+	var options []string
+	for _, directive := range strings.Split(value, ";") {
+		key, val, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch key = strings.ToLower(strings.TrimSpace(key)); key {
+		case "max-age":
+			if n, err := strconv.Atoi(strings.Trim(strings.TrimSpace(val), `"`)); err == nil && n >= 0 {
+				maxAge = n
+			}
+		case "includesubdomains", "preload":
+			options = append(options, key)
+		}
+	}
+
+	switch {
+	case maxAge < 0:
+		grade(name, GradeWeak, "the header has no valid max-age")
+	case maxAge == 0:
+		grade(name, GradeWeak, "max-age=0 tells browsers to forget the HSTS policy")
+	case maxAge < minHSTSMaxAge:
+		grade(name, GradeWeak, "max-age is %d seconds, shorter than the recommended %d", maxAge, minHSTSMaxAge)
+	case len(options) > 0:
+		grade(name, GradeGood, "max-age is %d seconds with %s", maxAge, strings.Join(options, " and "))
+	default:
+		grade(name, GradeGood, "max-age is %d seconds", maxAge)
+	}
+}
+
+// gradeReferrerPolicy grades the Referrer-Policy, of which browsers use the last valid token
+func gradeReferrerPolicy(values []string, grade gradeFunc) {
+	const name = "Referrer-Policy"
+	policy := ""
+	for _, value := range values {
+		for _, token := range strings.Split(value, ",") {
+			if token = strings.ToLower(strings.TrimSpace(token)); slices.Contains(referrerPolicies, token) {
+				policy = token
+			}
+		}
+	}
+
+	switch {
+	case len(values) == 0:
+		// browsers default to strict-origin-when-cross-origin, older ones to no-referrer-when-downgrade
+		grade(name, GradeMissing, "the referrer depends on the browser default")
+	case policy == "":
+		grade(name, GradeWeak, "the header has no valid policy")
+	case slices.Contains(goodReferrerPolicies, policy):
+		grade(name, GradeGood, "%s does not leak the page URL to other origins", policy)
+	default:
+		grade(name, GradeWeak, "%s sends the page URL or origin to other origins", policy)
+	}
+}
+
+// parseCSPHeaders parses the first enforced policy, or the first report-only policy when none is enforced
+func parseCSPHeaders(header http.Header) CSPInfo {
+	values := header.Values("Content-Security-Policy")
+	reportOnly := false
+	if len(values) == 0 {
+		values = header.Values("Content-Security-Policy-Report-Only")
+		reportOnly = true
+	}
+	csp := CSPInfo{Directives: map[string][]string{}, Warnings: []string{}}
+	for _, value := range values {
+		// a header may carry several comma separated policies
+		for _, policy := range strings.Split(value, ",") {
+			if directives := parseCSP(policy); len(directives) > 0 {
+				csp.Directives = directives
+				csp.ReportOnly = reportOnly
+				csp.Warnings = cspWarnings(directives)
+				return csp
+			}
+		}
+	}
+	return csp
+}
+
+// parseCSP splits a policy into its directives, keeping the first of repeated directives like browsers do
+func parseCSP(policy string) map[string][]string {
+	directives := make(map[string][]string)
+	for _, directive := range strings.Split(policy, ";") {
+		fields := strings.Fields(directive)
+		if len(fields) == 0 {
+			continue
+		}
+		name := strings.ToLower(fields[0])
+		if _, seen := directives[name]; seen {
+			continue
+		}
+		sources := []string{}
+		for _, source := range fields[1:] {
+			// keywords are case-insensitive, hosts and paths keep their case
+			if strings.HasPrefix(source, "'") {
+				source = strings.ToLower(source)
+			}
+			sources = append(sources, source)
+		}
+		directives[name] = sources
+	}
+	return directives
+}
+
+// cspWarnings reports sources that let injected scripts run and directives that allow any origin
+func cspWarnings(directives map[string][]string) []string {
+	warnings := []string{}
+
+	scriptDirective := "script-src"
+	if _, ok := directives[scriptDirective]; !ok {
+		scriptDirective = "default-src"
+	}
+	scripts, ok := directives[scriptDirective]
+	if !ok {
+		warnings = append(warnings, "the policy has no script-src or default-src, so scripts may load from anywhere")
+	}
+
+	// nonces, hashes and strict-dynamic make browsers ignore unsafe-inline
+	inlineIgnored := slices.ContainsFunc(scripts, func(s string) bool {
+		return strings.HasPrefix(s, "'nonce-") || strings.HasPrefix(s, "'sha") || s == "'strict-dynamic'"
+	})
+	if slices.Contains(scripts, "'unsafe-inline'") && !inlineIgnored {
+		warnings = append(warnings, fmt.Sprintf("%s allows 'unsafe-inline', so injected inline scripts run", scriptDirective))
+	}
+	if slices.Contains(scripts, "'unsafe-eval'") {
+		warnings = append(warnings, fmt.Sprintf("%s allows 'unsafe-eval', so strings can be run as code", scriptDirective))
+	}
+	for _, scheme := range []string{"http:", "https:", "data:"} {
+		if slices.Contains(scripts, scheme) {
+			warnings = append(warnings, fmt.Sprintf("%s allows scripts from any %s URL", scriptDirective, strings.TrimSuffix(scheme, ":")))
+		}
+	}
+
+	names := make([]string, 0, len(directives))
+	for name := range directives {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if slices.Contains(directives[name], "*") {
+			warnings = append(warnings, fmt.Sprintf("%s allows any origin with the * wildcard", name))
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,184 @@
+package analyzer
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// Table-driven tests for getSecurityHeaders
+func TestGetSecurityHeaders(t *testing.T) {
+	httpsURL, _ := url.Parse("https://example.com/")
+	httpURL, _ := url.Parse("http://example.com/")
+
+	tests := []struct {
+		name     string
+		baseURL  *url.URL
+		header   http.Header
+		expected map[string]string
+	}{
+		{
+			name:    "NoHeaders",
+			baseURL: httpsURL,
+			header:  http.Header{},
+			expected: map[string]string{
+				"Strict-Transport-Security":    GradeMissing,
+				"Content-Security-Policy":      GradeMissing,
+				"X-Frame-Options":              GradeMissing,
+				"X-Content-Type-Options":       GradeMissing,
+				"Referrer-Policy":              GradeMissing,
+				"Permissions-Policy":           GradeMissing,
+				"Cross-Origin-Opener-Policy":   GradeMissing,
+				"Cross-Origin-Embedder-Policy": GradeMissing,
+			},
+		},
+		{
+			name:    "Hardened",
+			baseURL: httpsURL,
+			header: http.Header{
+				"Strict-Transport-Security":    {"max-age=63072000; includeSubDomains; preload"},
+				"Content-Security-Policy":      {"default-src 'self'; script-src 'self' 'nonce-abc' 'unsafe-inline'; frame-ancestors 'none'"},
+				"X-Content-Type-Options":       {"nosniff"},
+				"Referrer-Policy":              {"no-referrer, strict-origin-when-cross-origin"},
+				"Permissions-Policy":           {"camera=(), geolocation=(self)"},
+				"Cross-Origin-Opener-Policy":   {"same-origin"},
+				"Cross-Origin-Embedder-Policy": {"require-corp"},
+			},
+			expected: map[string]string{
+				"Strict-Transport-Security":    GradeGood,
+				"Content-Security-Policy":      GradeGood,
+				"X-Frame-Options":              GradeGood,
+				"X-Content-Type-Options":       GradeGood,
+				"Referrer-Policy":              GradeGood,
+				"Permissions-Policy":           GradeGood,
+				"Cross-Origin-Opener-Policy":   GradeGood,
+				"Cross-Origin-Embedder-Policy": GradeGood,
+			},
+		},
+		{
+			name:    "Weak",
+			baseURL: httpsURL,
+			header: http.Header{
+				"Strict-Transport-Security":    {"max-age=3600"},
+				"Content-Security-Policy":      {"script-src * 'unsafe-eval'"},
+				"X-Frame-Options":              {"ALLOW-FROM https://partner.example"},
+				"X-Content-Type-Options":       {"sniff"},
+				"Referrer-Policy":              {"unsafe-url"},
+				"Permissions-Policy":           {"camera=*"},
+				"Cross-Origin-Opener-Policy":   {"unsafe-none"},
+				"Cross-Origin-Embedder-Policy": {"unsafe-none"},
+			},
+			expected: map[string]string{
+				"Strict-Transport-Security":    GradeWeak,
+				"Content-Security-Policy":      GradeWeak,
+				"X-Frame-Options":              GradeWeak,
+				"X-Content-Type-Options":       GradeWeak,
+				"Referrer-Policy":              GradeWeak,
+				"Permissions-Policy":           GradeWeak,
+				"Cross-Origin-Opener-Policy":   GradeWeak,
+				"Cross-Origin-Embedder-Policy": GradeWeak,
+			},
+		},
+		{
+			name:    "HSTSOverHTTPAndReportOnlyCSP",
+			baseURL: httpURL,
+			header: http.Header{
+				"Strict-Transport-Security":           {"max-age=63072000"},
+				"Content-Security-Policy-Report-Only": {"default-src 'self'; frame-ancestors 'none'"},
+				"X-Frame-Options":                     {"sameorigin"},
+			},
+			expected: map[string]string{
+				"Strict-Transport-Security":    GradeMissing,
+				"Content-Security-Policy":      GradeWeak,
+				"X-Frame-Options":              GradeGood,
+				"X-Content-Type-Options":       GradeMissing,
+				"Referrer-Policy":              GradeMissing,
+				"Permissions-Policy":           GradeMissing,
+				"Cross-Origin-Opener-Policy":   GradeMissing,
+				"Cross-Origin-Embedder-Policy": GradeMissing,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make(map[string]string)
+			for _, h := range getSecurityHeaders(tt.header, tt.baseURL).Headers {
+				got[h.Name] = h.Grade
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("grades = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+// Table-driven tests for parsing the Content-Security-Policy
+func TestParseCSPHeaders(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   http.Header
+		expected CSPInfo
+	}{
+		{
+			name:     "NoPolicy",
+			header:   http.Header{},
+			expected: CSPInfo{Directives: map[string][]string{}, Warnings: []string{}},
+		},
+		{
+			name:   "StrictPolicy",
+			header: http.Header{"Content-Security-Policy": {"default-src 'self'; img-src 'self' https://cdn.example.com; object-src 'none'"}},
+			expected: CSPInfo{
+				Directives: map[string][]string{
+					"default-src": {"'self'"},
+					"img-src":     {"'self'", "https://cdn.example.com"},
+					"object-src":  {"'none'"},
+				},
+				Warnings: []string{},
+			},
+		},
+		{
+			name:   "UnsafePolicy",
+			header: http.Header{"Content-Security-Policy": {"Default-Src 'SELF'; script-src 'self' 'unsafe-inline' 'unsafe-eval' https:; img-src *; script-src 'none'"}},
+			expected: CSPInfo{
+				Directives: map[string][]string{
+					"default-src": {"'self'"},
+					"script-src":  {"'self'", "'unsafe-inline'", "'unsafe-eval'", "https:"},
+					"img-src":     {"*"},
+				},
+				Warnings: []string{
+					"script-src allows 'unsafe-inline', so injected inline scripts run",
+					"script-src allows 'unsafe-eval', so strings can be run as code",
+					"script-src allows scripts from any https URL",
+					"img-src allows any origin with the * wildcard",
+				},
+			},
+		},
+		{
+			name:   "NoScriptRestriction",
+			header: http.Header{"Content-Security-Policy": {"frame-ancestors 'self', default-src 'none'"}},
+			expected: CSPInfo{
+				Directives: map[string][]string{"frame-ancestors": {"'self'"}},
+				Warnings:   []string{"the policy has no script-src or default-src, so scripts may load from anywhere"},
+			},
+		},
+		{
+			name:   "ReportOnly",
+			header: http.Header{"Content-Security-Policy-Report-Only": {"script-src 'self'"}},
+			expected: CSPInfo{
+				ReportOnly: true,
+				Directives: map[string][]string{"script-src": {"'self'"}},
+				Warnings:   []string{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseCSPHeaders(tt.header); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("parseCSPHeaders() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
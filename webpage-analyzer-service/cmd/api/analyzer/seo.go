@@ -0,0 +1,272 @@
+package analyzer
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// Statuses of audit checks
+const (
+	StatusPass = "pass"
+	StatusWarn = "warn"
+	StatusFail = "fail"
+)
+
+// Recommended lengths in characters, beyond which search engines truncate or ignore the text
+const (
+	minTitleLength       = 30
+	maxTitleLength       = 60
+	minDescriptionLength = 70
+	maxDescriptionLength = 160
+)
+
+// SEOAudit describes the metadata search engines read from the page
+type SEOAudit struct {
+	Description string `json:"description,omitempty"`
+	// Canonical is the resolved URL of the canonical link
+	Canonical string `json:"canonical,omitempty"`
+	// Robots lists the directives of meta robots tags and X-Robots-Tag headers
+	Robots   []string            `json:"robots"`
+	Hreflang []HreflangAlternate `json:"hreflang"`
+	Checks   []Check             `json:"checks"`
+}
+
+// HreflangAlternate is a translation of the page announced with <link rel="alternate" hreflang>
+type HreflangAlternate struct {
+	Lang string `json:"lang"`
+	URL  string `json:"url"`
+}
+
+// Check is the outcome of a single audit check
+type Check struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// checkFunc records the outcome of a check
+type checkFunc func(name, status, format string, args ...any)
+
+// hreflangPattern matches language codes with an optional region, or x-default
+var hreflangPattern = regexp.MustCompile(`^(?i)([a-z]{2,3}(-[a-z0-9]{2,8})*|x-default)$`)
+
+// robotsParameters are X-Robots-Tag directives that take a value after a colon,
+// as opposed to a user agent prefix such as "googlebot: noindex"
+var robotsParameters = map[string]bool{
+	"unavailable_after": true,
+	"max-snippet":       true,
+	"max-image-preview": true,
+	"max-video-preview": true,
+}
+
+// conflictingRobots are pairs of directives that contradict each other
+var conflictingRobots = [][2]string{{"index", "noindex"}, {"follow", "nofollow"}}
+
+// seoTags are the tags of the document the audit looks at
+type seoTags struct {
+	titles       []string
+	descriptions []string
+	canonicals   []string
+	robots       []string
+	robotsTags   int
+	hreflang     []HreflangAlternate
+}
+
+// getSEOAudit checks the title, description, canonical link, robots directives and
+// hreflang alternates of the document. Links are resolved against baseURL.
+func getSEOAudit(doc *html.Node, baseURL *url.URL, header http.Header) SEOAudit {
+	tags := collectSEOTags(doc, baseURL)
+	for _, value := range header.Values("X-Robots-Tag") {
+		tags.robots = append(tags.robots, parseRobots(value, true)...)
+	}
+
+	audit := SEOAudit{
+		Robots:   tags.robots,
+		Hreflang: tags.hreflang,
+		Checks:   []Check{},
+	}
+	if len(tags.descriptions) > 0 {
+		audit.Description = tags.descriptions[0]
+	}
+	if len(tags.canonicals) > 0 {
+		audit.Canonical = tags.canonicals[0]
+	}
+
+	check := func(name, status, format string, args ...any) {
+		audit.Checks = append(audit.Checks, Check{Name: name, Status: status, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch {
+	case len(tags.titles) == 0 || tags.titles[0] == "":
+		check("title", StatusFail, "the page has no title")
+	case len(tags.titles) > 1:
+		check("title", StatusFail, "the page has %d title tags", len(tags.titles))
+	default:
+		checkLength(check, "title", tags.titles[0], minTitleLength, maxTitleLength)
+	}
+
+	switch {
+	case len(tags.descriptions) == 0 || tags.descriptions[0] == "":
+		check("description", StatusFail, "the page has no meta description")
+	case len(tags.descriptions) > 1:
+		check("description", StatusFail, "the page has %d meta descriptions", len(tags.descriptions))
+	default:
+		checkLength(check, "description", tags.descriptions[0], minDescriptionLength, maxDescriptionLength)
+	}
+
+	switch {
+	case len(tags.canonicals) == 0:
+		check("canonical", StatusWarn, "the page has no canonical link")
+	case countDistinct(tags.canonicals) > 1:
+		check("canonical", StatusFail, "the page has conflicting canonical links: %s", strings.Join(tags.canonicals, ", "))
+	case len(tags.canonicals) > 1:
+		check("canonical", StatusWarn, "the page repeats its canonical link %d times", len(tags.canonicals))
+	default:
+		check("canonical", StatusPass, "the canonical URL is %s", tags.canonicals[0])
+	}
+
+	checkRobots(check, tags)
+	checkHreflang(check, tags.hreflang)
+
+	return audit
+}
+
+// collectSEOTags gathers the tags the audit looks at
+func collectSEOTags(doc *html.Node, baseURL *url.URL) seoTags {
+	tags := seoTags{robots: []string{}, hreflang: []HreflangAlternate{}}
+	var traverse func(*html.Node)
+	traverse = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.Data {
+			case "title":
+				tags.titles = append(tags.titles, textContent(n))
+			case "meta":
+				content := strings.TrimSpace(attrValue(n, "content"))
+				switch strings.ToLower(attrValue(n, "name")) {
+				case "description":
+					tags.descriptions = append(tags.descriptions, content)
+				case "robots":
+					tags.robotsTags++
+					tags.robots = append(tags.robots, parseRobots(content, false)...)
+				}
+			case "link":
+				rel := strings.Fields(strings.ToLower(attrValue(n, "rel")))
+				href, hasHref := getAttr(n, "href")
+				switch {
+				case !hasHref:
+				case slices.Contains(rel, "canonical"):
+					tags.canonicals = append(tags.canonicals, resolveURL(baseURL, href))
+				case slices.Contains(rel, "alternate"):
+					if lang, ok := getAttr(n, "hreflang"); ok {
+						tags.hreflang = append(tags.hreflang, HreflangAlternate{Lang: strings.TrimSpace(lang), URL: resolveURL(baseURL, href)})
+					}
+				}
+			case "svg":
+				// the <title> of inline SVG images is not the page title
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			traverse(c)
+		}
+	}
+	traverse(doc)
+	return tags
+}
+
+// parseRobots splits a robots directive list into lower case directives. Header values
+// may start with the user agent they apply to, which is dropped.
+func parseRobots(value string, header bool) []string {
+	if header {
+		if agent, rest, ok := strings.Cut(value, ":"); ok && !strings.ContainsAny(agent, ", ") && !robotsParameters[strings.ToLower(agent)] {
+			value = rest
+		}
+	}
+	var directives []string
+	for _, directive := range strings.Split(value, ",") {
+		if directive = strings.ToLower(strings.TrimSpace(directive)); directive != "" {
+			directives = append(directives, directive)
+		}
+	}
+	return directives
+}
+
+// checkLength reports whether text is within the recommended length for name
+func checkLength(check checkFunc, name, text string, minLength, maxLength int) {
+	length := utf8.RuneCountInString(text)
+	switch {
+	case length < minLength:
+		check(name, StatusWarn, "the %s is %d characters long, shorter than the recommended %d", name, length, minLength)
+	case length > maxLength:
+		check(name, StatusWarn, "the %s is %d characters long, longer than the recommended %d", name, length, maxLength)
+	default:
+		check(name, StatusPass, "the %s is %d characters long", name, length)
+	}
+}
+
+// checkRobots reports directives that keep the page out of search results or contradict each other
+func checkRobots(check checkFunc, tags seoTags) {
+	for _, pair := range conflictingRobots {
+		if slices.Contains(tags.robots, pair[0]) && slices.Contains(tags.robots, pair[1]) {
+			check("robots", StatusFail, "the robots directives contain both %s and %s", pair[0], pair[1])
+			return
+		}
+	}
+	switch {
+	case slices.Contains(tags.robots, "noindex") || slices.Contains(tags.robots, "none"):
+		check("robots", StatusWarn, "the robots directives keep the page out of search results")
+	case tags.robotsTags > 1:
+		check("robots", StatusWarn, "the page has %d meta robots tags", tags.robotsTags)
+	default:
+		check("robots", StatusPass, "search engines may index the page")
+	}
+}
+
+// checkHreflang reports invalid, conflicting and incomplete hreflang alternates
+func checkHreflang(check checkFunc, alternates []HreflangAlternate) {
+	if len(alternates) == 0 {
+		return
+	}
+
+	urls := make(map[string]string)
+	hasDefault := false
+	failed := false
+	for _, alternate := range alternates {
+		lang := strings.ToLower(alternate.Lang)
+		if !hreflangPattern.MatchString(lang) {
+			check("hreflang", StatusFail, "%q is not a valid hreflang language code", alternate.Lang)
+			failed = true
+			continue
+		}
+		if previous, ok := urls[lang]; ok && previous != alternate.URL {
+			check("hreflang", StatusFail, "hreflang %s points to both %s and %s", alternate.Lang, previous, alternate.URL)
+			failed = true
+		}
+		urls[lang] = alternate.URL
+		hasDefault = hasDefault || lang == "x-default"
+	}
+
+	switch {
+	case failed:
+	case !hasDefault:
+		check("hreflang", StatusWarn, "the hreflang alternates have no x-default")
+	default:
+		check("hreflang", StatusPass, "the page has %d hreflang alternates", len(alternates))
+	}
+}
+
+// countDistinct returns the number of different values
+func countDistinct(values []string) int {
+	distinct := make(map[string]bool)
+	for _, v := range values {
+		distinct[v] = true
+	}
+	return len(distinct)
+}
@@ -0,0 +1,139 @@
+package analyzer
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// checkStatuses maps the names of the checks to their statuses, joining repeated checks
+func checkStatuses(checks []Check) map[string]string {
+	statuses := make(map[string]string)
+	for _, c := range checks {
+		if statuses[c.Name] != "" {
+			statuses[c.Name] += "," + c.Status
+		} else {
+			statuses[c.Name] = c.Status
+		}
+	}
+	return statuses
+}
+
+// Table-driven tests for getSEOAudit
+func TestGetSEOAudit(t *testing.T) {
+	baseURL, _ := url.Parse("https://www.example.com/products/")
+	goodTitle := "<title>Handmade leather bags and wallets | Example</title>"
+	goodDescription := `<meta name="description" content="` + strings.Repeat("Handmade leather goods. ", 4) + `">`
+
+	tests := []struct {
+		name             string
+		html             string
+		header           http.Header
+		expectedStatuses map[string]string
+		expectedRobots   []string
+		expectedCanon    string
+		expectedHreflang []HreflangAlternate
+	}{
+		{
+			name: "Complete",
+			html: `<html><head>` + goodTitle + goodDescription + `
+				<link rel="canonical" href="/products/bags">
+				<link rel="alternate" hreflang="en" href="https://www.example.com/en/">
+				<link rel="alternate" hreflang="de-DE" href="https://www.example.com/de/">
+				<link rel="alternate" hreflang="x-default" href="https://www.example.com/">
+			</head></html>`,
+			expectedStatuses: map[string]string{"title": StatusPass, "description": StatusPass, "canonical": StatusPass, "robots": StatusPass, "hreflang": StatusPass},
+			expectedRobots:   []string{},
+			expectedCanon:    "https://www.example.com/products/bags",
+			expectedHreflang: []HreflangAlternate{
+				{Lang: "en", URL: "https://www.example.com/en/"},
+				{Lang: "de-DE", URL: "https://www.example.com/de/"},
+				{Lang: "x-default", URL: "https://www.example.com/"},
+			},
+		},
+		{
+			name:             "Missing",
+			html:             `<html><head></head><body><svg><title>Icon</title></svg></body></html>`,
+			expectedStatuses: map[string]string{"title": StatusFail, "description": StatusFail, "canonical": StatusWarn, "robots": StatusPass},
+			expectedRobots:   []string{},
+		},
+		{
+			name:             "ShortTitleLongDescription",
+			html:             `<html><head><title>Bags</title><meta name="description" content="` + strings.Repeat("x", 200) + `"></head></html>`,
+			expectedStatuses: map[string]string{"title": StatusWarn, "description": StatusWarn, "canonical": StatusWarn, "robots": StatusPass},
+			expectedRobots:   []string{},
+		},
+		{
+			name: "Duplicates",
+			html: `<html><head>` + goodTitle + goodTitle + goodDescription + goodDescription + `
+				<link rel="canonical" href="https://www.example.com/a">
+				<link rel="canonical" href="https://www.example.com/b">
+			</head></html>`,
+			expectedStatuses: map[string]string{"title": StatusFail, "description": StatusFail, "canonical": StatusFail, "robots": StatusPass},
+			expectedRobots:   []string{},
+			expectedCanon:    "https://www.example.com/a",
+		},
+		{
+			name:             "NoindexHeader",
+			html:             `<html><head>` + goodTitle + goodDescription + `<meta name="robots" content="index, follow"></head></html>`,
+			header:           http.Header{"X-Robots-Tag": {"googlebot: noindex"}},
+			expectedStatuses: map[string]string{"title": StatusPass, "description": StatusPass, "canonical": StatusWarn, "robots": StatusFail},
+			expectedRobots:   []string{"index", "follow", "noindex"},
+		},
+		{
+			name:             "NoindexMeta",
+			html:             `<html><head>` + goodTitle + goodDescription + `<meta name="ROBOTS" content="NOINDEX"></head></html>`,
+			header:           http.Header{"X-Robots-Tag": {"max-snippet: 20"}},
+			expectedStatuses: map[string]string{"title": StatusPass, "description": StatusPass, "canonical": StatusWarn, "robots": StatusWarn},
+			expectedRobots:   []string{"noindex", "max-snippet: 20"},
+		},
+		{
+			name: "InvalidHreflang",
+			html: `<html><head>` + goodTitle + goodDescription + `
+				<link rel="alternate" hreflang="english" href="https://www.example.com/en/">
+				<link rel="alternate" hreflang="fr" href="https://www.example.com/fr/">
+				<link rel="alternate" hreflang="fr" href="https://www.example.com/fr-fr/">
+			</head></html>`,
+			expectedStatuses: map[string]string{"title": StatusPass, "description": StatusPass, "canonical": StatusWarn, "robots": StatusPass, "hreflang": StatusFail + "," + StatusFail},
+			expectedRobots:   []string{},
+			expectedHreflang: []HreflangAlternate{
+				{Lang: "english", URL: "https://www.example.com/en/"},
+				{Lang: "fr", URL: "https://www.example.com/fr/"},
+				{Lang: "fr", URL: "https://www.example.com/fr-fr/"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parseHTML(tt.html)
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+			header := tt.header
+			if header == nil {
+				header = http.Header{}
+			}
+			expectedHreflang := tt.expectedHreflang
+			if expectedHreflang == nil {
+				expectedHreflang = []HreflangAlternate{}
+			}
+
+			got := getSEOAudit(doc, baseURL, header)
+			if statuses := checkStatuses(got.Checks); !reflect.DeepEqual(statuses, tt.expectedStatuses) {
+				t.Errorf("check statuses = %v, want %v (%+v)", statuses, tt.expectedStatuses, got.Checks)
+			}
+			if !reflect.DeepEqual(got.Robots, tt.expectedRobots) {
+				t.Errorf("Robots = %q, want %q", got.Robots, tt.expectedRobots)
+			}
+			if got.Canonical != tt.expectedCanon {
+				t.Errorf("Canonical = %v, want %v", got.Canonical, tt.expectedCanon)
+			}
+			if !reflect.DeepEqual(got.Hreflang, expectedHreflang) {
+				t.Errorf("Hreflang = %+v, want %+v", got.Hreflang, expectedHreflang)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package analyzer
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/html"
+)
+
+// requiredSocialProperties must be present for social networks to render a preview,
+// see https://ogp.me/#metadata and https://developer.x.com/en/docs/x-for-websites/cards
+var requiredSocialProperties = []string{"og:title", "og:type", "og:image", "og:url", "twitter:card"}
+
+// socialImageProperties hold image URLs, in order of preference
+var socialImageProperties = []string{"og:image", "og:image:url", "og:image:secure_url", "twitter:image", "twitter:image:src"}
+
+// SocialMeta describes the Open Graph and Twitter Card metadata of the page
+type SocialMeta struct {
+	// OpenGraph maps og:* properties to their first value
+	OpenGraph map[string]string `json:"openGraph"`
+	// Twitter maps twitter:* properties to their first value
+	Twitter map[string]string `json:"twitter"`
+	Images  []SocialImage     `json:"images"`
+	// Missing lists the required properties the page lacks
+	Missing []string      `json:"missing"`
+	Preview SocialPreview `json:"preview"`
+}
+
+// SocialImage is an image referenced by the social metadata
+type SocialImage struct {
+	// URL is the image URL resolved against the page
+	URL string `json:"url"`
+	// Properties lists the properties referencing the image
+	Properties []string `json:"properties"`
+	Reachable  bool     `json:"reachable"`
+}
+
+// SocialPreview is what a social network would show when the page is shared,
+// falling back to the page title and meta description like the networks do
+type SocialPreview struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Image       string `json:"image,omitempty"`
+	URL         string `json:"url"`
+	Domain      string `json:"domain"`
+	SiteName    string `json:"siteName,omitempty"`
+	// Card is the Twitter card type, summary when the page does not set one
+	Card string `json:"card"`
+}
+
+// getSocialMeta extracts the og:* and twitter:* properties of the document and the preview
+// they produce, resolving URLs against baseURL. Images are not checked yet.
+func getSocialMeta(doc *html.Node, baseURL *url.URL) SocialMeta {
+	meta := SocialMeta{
+		OpenGraph: make(map[string]string),
+		Twitter:   make(map[string]string),
+		Images:    []SocialImage{},
+		Missing:   []string{},
+	}
+
+	description := ""
+	var traverse func(*html.Node)
+	traverse = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "meta" {
+			// Open Graph uses property and Twitter uses name, but pages mix them up
+			property := attrValue(n, "property")
+			if property == "" {
+				property = attrValue(n, "name")
+			}
+			property = strings.ToLower(strings.TrimSpace(property))
+			content := strings.TrimSpace(attrValue(n, "content"))
+
+			var properties map[string]string
+			switch {
+			case property == "description" && description == "":
+				description = content
+			case strings.HasPrefix(property, "og:"):
+				properties = meta.OpenGraph
+			case strings.HasPrefix(property, "twitter:"):
+				properties = meta.Twitter
+			}
+			if _, seen := properties[property]; properties != nil && !seen && content != "" {
+				properties[property] = content
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			traverse(c)
+		}
+	}
+	traverse(doc)
+
+	property := func(name string) string {
+		if strings.HasPrefix(name, "og:") {
+			return meta.OpenGraph[name]
+		}
+		return meta.Twitter[name]
+	}
+
+	for _, name := range socialImageProperties {
+		value := property(name)
+		if value == "" {
+			continue
+		}
+		imageURL := resolveURL(baseURL, value)
+		found := false
+		for i := range meta.Images {
+			if meta.Images[i].URL == imageURL {
+				meta.Images[i].Properties = append(meta.Images[i].Properties, name)
+				found = true
+			}
+		}
+		if !found {
+			meta.Images = append(meta.Images, SocialImage{URL: imageURL, Properties: []string{name}})
+		}
+	}
+
+	for _, name := range requiredSocialProperties {
+		if property(name) != "" {
+			continue
+		}
+		// an og:image:url or og:image:secure_url stands in for og:image
+		if name == "og:image" && (property("og:image:url") != "" || property("og:image:secure_url") != "") {
+			continue
+		}
+		meta.Missing = append(meta.Missing, name)
+	}
+
+	first := func(values ...string) string {
+		for _, v := range values {
+			if v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+	meta.Preview = SocialPreview{
+		Title:       first(meta.OpenGraph["og:title"], meta.Twitter["twitter:title"], getPageTitle(doc)),
+		Description: first(meta.OpenGraph["og:description"], meta.Twitter["twitter:description"], description),
+		URL:         resolveURL(baseURL, first(meta.OpenGraph["og:url"], baseURL.String())),
+		SiteName:    meta.OpenGraph["og:site_name"],
+		Card:        first(meta.Twitter["twitter:card"], "summary"),
+	}
+	if len(meta.Images) > 0 {
+		meta.Preview.Image = meta.Images[0].URL
+	}
+	if u, err := url.Parse(meta.Preview.URL); err == nil {
+		meta.Preview.Domain = strings.TrimPrefix(u.Hostname(), "www.")
+	}
+
+	return meta
+}
+
+// checkSocialImages checks whether the images of the social metadata are reachable,
+// as long as the link check budget of ctx lasts. Unchecked images are reported unreachable.
+func (a *Analyzer) checkSocialImages(ctx context.Context, images []SocialImage) {
+	budget := a.budgetFrom(ctx)
+	var wg sync.WaitGroup
+	for i := range images {
+		if !budget.take() {
+			budget.skip()
+			continue
+		}
+		wg.Add(1)
+		budget.acquire()
+		go func(image *SocialImage) {
+			defer wg.Done()
+			defer budget.release()
+			reachable, checked := a.isAccessible(ctx, image.URL)
+			if !checked {
+				budget.skip()
+			}
+			image.Reachable = reachable
+		}(&images[i])
+	}
+	wg.Wait()
+}
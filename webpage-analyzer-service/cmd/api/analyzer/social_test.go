@@ -0,0 +1,132 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// Table-driven tests for getSocialMeta
+func TestGetSocialMeta(t *testing.T) {
+	baseURL, _ := url.Parse("https://www.example.com/blog/post")
+
+	tests := []struct {
+		name     string
+		html     string
+		expected SocialMeta
+	}{
+		{
+			name: "Complete",
+			html: `<html><head><title>Page title</title>
+				<meta property="og:title" content="Shared title">
+				<meta property="og:type" content="article">
+				<meta property="og:url" content="https://www.example.com/blog/post">
+				<meta property="og:image" content="/images/cover.png">
+				<meta property="og:site_name" content="Example Blog">
+				<meta name="twitter:card" content="summary_large_image">
+				<meta name="twitter:image" content="https://www.example.com/images/cover.png">
+				<meta name="description" content="Page description">
+			</head></html>`,
+			expected: SocialMeta{
+				OpenGraph: map[string]string{
+					"og:title":     "Shared title",
+					"og:type":      "article",
+					"og:url":       "https://www.example.com/blog/post",
+					"og:image":     "/images/cover.png",
+					"og:site_name": "Example Blog",
+				},
+				Twitter: map[string]string{
+					"twitter:card":  "summary_large_image",
+					"twitter:image": "https://www.example.com/images/cover.png",
+				},
+				Images: []SocialImage{
+					{URL: "https://www.example.com/images/cover.png", Properties: []string{"og:image", "twitter:image"}},
+				},
+				Missing: []string{},
+				Preview: SocialPreview{
+					Title:       "Shared title",
+					Description: "Page description",
+					Image:       "https://www.example.com/images/cover.png",
+					URL:         "https://www.example.com/blog/post",
+					Domain:      "example.com",
+					SiteName:    "Example Blog",
+					Card:        "summary_large_image",
+				},
+			},
+		},
+		{
+			name: "FallsBackToPage",
+			html: `<html><head><title>Page title</title>
+				<meta name="twitter:title" content="Tweet title">
+				<meta property="og:image:secure_url" content="cover.jpg">
+			</head></html>`,
+			expected: SocialMeta{
+				OpenGraph: map[string]string{"og:image:secure_url": "cover.jpg"},
+				Twitter:   map[string]string{"twitter:title": "Tweet title"},
+				Images: []SocialImage{
+					{URL: "https://www.example.com/blog/cover.jpg", Properties: []string{"og:image:secure_url"}},
+				},
+				Missing: []string{"og:title", "og:type", "og:url", "twitter:card"},
+				Preview: SocialPreview{
+					Title:  "Tweet title",
+					Image:  "https://www.example.com/blog/cover.jpg",
+					URL:    "https://www.example.com/blog/post",
+					Domain: "example.com",
+					Card:   "summary",
+				},
+			},
+		},
+		{
+			name: "None",
+			html: `<html><head></head></html>`,
+			expected: SocialMeta{
+				OpenGraph: map[string]string{},
+				Twitter:   map[string]string{},
+				Images:    []SocialImage{},
+				Missing:   []string{"og:title", "og:type", "og:image", "og:url", "twitter:card"},
+				Preview: SocialPreview{
+					URL:    "https://www.example.com/blog/post",
+					Domain: "example.com",
+					Card:   "summary",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parseHTML(tt.html)
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+			if got := getSocialMeta(doc, baseURL); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("getSocialMeta() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCheckSocialImages(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/cover.png" {
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	images := []SocialImage{
+		{URL: ts.URL + "/cover.png"},
+		{URL: ts.URL + "/missing.png"},
+	}
+	New(DefaultOptions()).checkSocialImages(context.Background(), images)
+
+	if !images[0].Reachable {
+		t.Errorf("Reachable(%s) = false, want true", images[0].URL)
+	}
+	if images[1].Reachable {
+		t.Errorf("Reachable(%s) = true, want false", images[1].URL)
+	}
+}
@@ -0,0 +1,296 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Formats structured data is embedded in
+const (
+	FormatJSONLD    = "json-ld"
+	FormatMicrodata = "microdata"
+	FormatRDFa      = "rdfa"
+)
+
+// StructuredData describes the schema.org entities embedded in the page
+type StructuredData struct {
+	Entities []StructuredEntity `json:"entities"`
+	// Types counts the entities of every type
+	Types  map[string]int `json:"types"`
+	Errors []string       `json:"errors"`
+}
+
+// StructuredEntity is a top-level item found in JSON-LD, Microdata or RDFa
+type StructuredEntity struct {
+	Format string `json:"format"`
+	// Type is the schema.org type without its vocabulary, e.g. Product
+	Type string `json:"type"`
+	// Properties maps property names to a value, a list of values or a nested entity
+	Properties map[string]any `json:"properties"`
+	// Missing lists the required properties of the type the entity lacks;
+	// alternatives are joined with " or "
+	Missing []string `json:"missing"`
+}
+
+// requiredStructuredProperties lists the properties search engines require for
+// rich results of common types. Every entry needs at least one of its alternatives.
+var requiredStructuredProperties = map[string][][]string{
+	"Article":        {{"headline"}, {"author"}, {"datePublished"}},
+	"NewsArticle":    {{"headline"}, {"author"}, {"datePublished"}},
+	"BlogPosting":    {{"headline"}, {"author"}, {"datePublished"}},
+	"Product":        {{"name"}, {"offers", "review", "aggregateRating"}},
+	"BreadcrumbList": {{"itemListElement"}},
+	"Organization":   {{"name"}, {"url"}},
+}
+
+// getStructuredData extracts JSON-LD, Microdata and RDFa entities from the document,
+// resolving URL values against baseURL
+func getStructuredData(doc *html.Node, baseURL *url.URL) StructuredData {
+	data := StructuredData{
+		Entities: []StructuredEntity{},
+		Types:    make(map[string]int),
+		Errors:   []string{},
+	}
+
+	scripts := 0
+	var traverse func(*html.Node)
+	traverse = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch {
+			case n.Data == "script" && strings.EqualFold(strings.TrimSpace(attrValue(n, "type")), "application/ld+json"):
+				scripts++
+				entities, err := parseJSONLD(rawText(n))
+				if err != nil {
+					data.Errors = append(data.Errors, fmt.Sprintf("JSON-LD script %d: %v", scripts, err))
+				}
+				data.Entities = append(data.Entities, entities...)
+				return
+			case hasAttr(n, "itemscope") && !hasAttr(n, "itemprop"):
+				data.Entities = append(data.Entities, StructuredEntity{
+					Format:     FormatMicrodata,
+					Type:       schemaName(firstField(attrValue(n, "itemtype"))),
+					Properties: microdataProperties(n, baseURL),
+				})
+			case hasAttr(n, "typeof") && !hasAttr(n, "property"):
+				data.Entities = append(data.Entities, StructuredEntity{
+					Format:     FormatRDFa,
+					Type:       schemaName(firstField(attrValue(n, "typeof"))),
+					Properties: rdfaProperties(n, baseURL),
+				})
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			traverse(c)
+		}
+	}
+	traverse(doc)
+
+	for i := range data.Entities {
+		entity := &data.Entities[i]
+		entity.Missing = missingStructuredProperties(entity.Type, entity.Properties)
+		if entity.Type != "" {
+			data.Types[entity.Type]++
+		}
+	}
+
+	return data
+}
+
+// parseJSONLD returns the top-level nodes of a JSON-LD script, including the nodes of an @graph
+func parseJSONLD(script string) ([]StructuredEntity, error) {
+	var value any
+	if err := json.Unmarshal([]byte(script), &value); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			line := strings.Count(script[:syntaxErr.Offset], "\n") + 1
+			return nil, fmt.Errorf("invalid JSON on line %d: %v", line, err)
+		}
+		return nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+
+	var entities []StructuredEntity
+	var collect func(any)
+	collect = func(value any) {
+		switch v := value.(type) {
+		case []any:
+			for _, item := range v {
+				collect(item)
+			}
+		case map[string]any:
+			if graph, ok := v["@graph"]; ok {
+				collect(graph)
+				return
+			}
+			properties := make(map[string]any)
+			for key, val := range v {
+				if key != "@context" && key != "@type" {
+					properties[key] = val
+				}
+			}
+			entities = append(entities, StructuredEntity{
+				Format:     FormatJSONLD,
+				Type:       schemaName(jsonLDType(v["@type"])),
+				Properties: properties,
+			})
+		}
+	}
+	collect(value)
+	return entities, nil
+}
+
+// jsonLDType returns the first type of a JSON-LD @type, which may be a string or a list
+func jsonLDType(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []any:
+		if len(v) > 0 {
+			s, _ := v[0].(string)
+			return s
+		}
+	}
+	return ""
+}
+
+// microdataProperties returns the itemprop values of the item n, descending into nested items
+func microdataProperties(item *html.Node, baseURL *url.URL) map[string]any {
+	properties := make(map[string]any)
+	var traverse func(*html.Node)
+	traverse = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			names := strings.Fields(attrValue(c, "itemprop"))
+			if hasAttr(c, "itemscope") {
+				if len(names) > 0 {
+					nested := microdataProperties(c, baseURL)
+					if itemType := schemaName(firstField(attrValue(c, "itemtype"))); itemType != "" {
+						nested["@type"] = itemType
+					}
+					for _, name := range names {
+						addProperty(properties, name, nested)
+					}
+				}
+				// the properties of a nested item belong to that item
+				continue
+			}
+			for _, name := range names {
+				addProperty(properties, name, elementValue(c, baseURL, "content", "src", "href", "data", "value", "datetime"))
+			}
+			traverse(c)
+		}
+	}
+	traverse(item)
+	return properties
+}
+
+// rdfaProperties returns the property values of the RDFa resource n, descending into nested resources
+func rdfaProperties(resource *html.Node, baseURL *url.URL) map[string]any {
+	properties := make(map[string]any)
+	var traverse func(*html.Node)
+	traverse = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			names := strings.Fields(attrValue(c, "property"))
+			if hasAttr(c, "typeof") {
+				if len(names) > 0 {
+					nested := rdfaProperties(c, baseURL)
+					if resourceType := schemaName(firstField(attrValue(c, "typeof"))); resourceType != "" {
+						nested["@type"] = resourceType
+					}
+					for _, name := range names {
+						addProperty(properties, schemaName(name), nested)
+					}
+				}
+				continue
+			}
+			for _, name := range names {
+				addProperty(properties, schemaName(name), elementValue(c, baseURL, "content", "resource", "href", "src", "datetime"))
+			}
+			traverse(c)
+		}
+	}
+	traverse(resource)
+	return properties
+}
+
+// urlAttributes hold URLs that are resolved against the page
+var urlAttributes = map[string]bool{"src": true, "href": true, "data": true, "resource": true}
+
+// elementValue returns the first of attrs n has, or its text. URLs are resolved against baseURL.
+func elementValue(n *html.Node, baseURL *url.URL, attrs ...string) string {
+	for _, attr := range attrs {
+		if val, ok := getAttr(n, attr); ok {
+			if urlAttributes[attr] {
+				return resolveURL(baseURL, val)
+			}
+			return strings.TrimSpace(val)
+		}
+	}
+	return textContent(n)
+}
+
+// addProperty sets name to value, turning repeated properties into a list
+func addProperty(properties map[string]any, name string, value any) {
+	switch existing := properties[name].(type) {
+	case nil:
+		properties[name] = value
+	case []any:
+		properties[name] = append(existing, value)
+	default:
+		properties[name] = []any{existing, value}
+	}
+}
+
+// missingStructuredProperties returns the required properties of entityType that properties lack
+func missingStructuredProperties(entityType string, properties map[string]any) []string {
+	missing := []string{}
+	for _, alternatives := range requiredStructuredProperties[entityType] {
+		found := false
+		for _, name := range alternatives {
+			if value, ok := properties[name]; ok && value != "" && value != nil {
+				found = true
+			}
+		}
+		if !found {
+			missing = append(missing, strings.Join(alternatives, " or "))
+		}
+	}
+	return missing
+}
+
+// schemaName strips the vocabulary from a type or property such as
+// https://schema.org/Product or schema:name
+func schemaName(name string) string {
+	if i := strings.LastIndexAny(name, "/#:"); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+// firstField returns the first space separated value of s
+func firstField(s string) string {
+	if fields := strings.Fields(s); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
+
+// rawText returns the text children of n unchanged, as the parser keeps script contents
+func rawText(n *html.Node) string {
+	var sb strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.TextNode {
+			sb.WriteString(c.Data)
+		}
+	}
+	return sb.String()
+}
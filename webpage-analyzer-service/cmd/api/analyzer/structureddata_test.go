@@ -0,0 +1,192 @@
+package analyzer
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+// Table-driven tests for getStructuredData
+func TestGetStructuredData(t *testing.T) {
+	baseURL, _ := url.Parse("https://example.com/products/1")
+
+	tests := []struct {
+		name     string
+		html     string
+		expected StructuredData
+	}{
+		{
+			name:     "NoStructuredData",
+			html:     `<html><body><p>Hello</p></body></html>`,
+			expected: StructuredData{Entities: []StructuredEntity{}, Types: map[string]int{}, Errors: []string{}},
+		},
+		{
+			name: "JSONLDOrganization",
+			html: `<html><head><script type="application/ld+json">
+				{"@context": "https://schema.org", "@type": "Organization", "name": "Example", "url": "https://example.com"}
+			</script></head></html>`,
+			expected: StructuredData{
+				Entities: []StructuredEntity{{
+					Format:     FormatJSONLD,
+					Type:       "Organization",
+					Properties: map[string]any{"name": "Example", "url": "https://example.com"},
+					Missing:    []string{},
+				}},
+				Types:  map[string]int{"Organization": 1},
+				Errors: []string{},
+			},
+		},
+		{
+			name: "JSONLDGraphWithMissingProperties",
+			html: `<html><head><script type="application/ld+json">
+				{"@context": "https://schema.org", "@graph": [
+					{"@type": "Article", "headline": "News"},
+					{"@type": ["Product", "Thing"], "name": "Shoe", "offers": {"@type": "Offer", "price": "10"}}
+				]}
+			</script></head></html>`,
+			expected: StructuredData{
+				Entities: []StructuredEntity{
+					{
+						Format:     FormatJSONLD,
+						Type:       "Article",
+						Properties: map[string]any{"headline": "News"},
+						Missing:    []string{"author", "datePublished"},
+					},
+					{
+						Format:     FormatJSONLD,
+						Type:       "Product",
+						Properties: map[string]any{"name": "Shoe", "offers": map[string]any{"@type": "Offer", "price": "10"}},
+						Missing:    []string{},
+					},
+				},
+				Types:  map[string]int{"Article": 1, "Product": 1},
+				Errors: []string{},
+			},
+		},
+		{
+			name: "MicrodataProductWithNestedOffer",
+			html: `<html><body><div itemscope itemtype="https://schema.org/Product">
+				<h1 itemprop="name">Shoe</h1>
+				<img itemprop="image" src="/shoe.png">
+				<div itemprop="offers" itemscope itemtype="https://schema.org/Offer">
+					<meta itemprop="price" content="10.00">
+					<span itemprop="priceCurrency">EUR</span>
+				</div>
+			</div></body></html>`,
+			expected: StructuredData{
+				Entities: []StructuredEntity{{
+					Format: FormatMicrodata,
+					Type:   "Product",
+					Properties: map[string]any{
+						"name":   "Shoe",
+						"image":  "https://example.com/shoe.png",
+						"offers": map[string]any{"@type": "Offer", "price": "10.00", "priceCurrency": "EUR"},
+					},
+					Missing: []string{},
+				}},
+				Types:  map[string]int{"Product": 1},
+				Errors: []string{},
+			},
+		},
+		{
+			name: "MicrodataBreadcrumbWithRepeatedProperty",
+			html: `<html><body><ol itemscope itemtype="https://schema.org/BreadcrumbList">
+				<li itemprop="itemListElement" itemscope itemtype="https://schema.org/ListItem"><a itemprop="item" href="/"><span itemprop="name">Home</span></a></li>
+				<li itemprop="itemListElement" itemscope itemtype="https://schema.org/ListItem"><span itemprop="name">Shoes</span></li>
+			</ol></body></html>`,
+			expected: StructuredData{
+				Entities: []StructuredEntity{{
+					Format: FormatMicrodata,
+					Type:   "BreadcrumbList",
+					Properties: map[string]any{
+						"itemListElement": []any{
+							map[string]any{"@type": "ListItem", "item": "https://example.com/", "name": "Home"},
+							map[string]any{"@type": "ListItem", "name": "Shoes"},
+						},
+					},
+					Missing: []string{},
+				}},
+				Types:  map[string]int{"BreadcrumbList": 1},
+				Errors: []string{},
+			},
+		},
+		{
+			name: "RDFaOrganizationMissingURL",
+			html: `<html><body><div vocab="https://schema.org/" typeof="Organization">
+				<span property="name">Example</span>
+			</div></body></html>`,
+			expected: StructuredData{
+				Entities: []StructuredEntity{{
+					Format:     FormatRDFa,
+					Type:       "Organization",
+					Properties: map[string]any{"name": "Example"},
+					Missing:    []string{"url"},
+				}},
+				Types:  map[string]int{"Organization": 1},
+				Errors: []string{},
+			},
+		},
+		{
+			name: "ProductWithoutOffersOrReviews",
+			html: `<html><body><div itemscope itemtype="http://schema.org/Product"><span itemprop="name">Shoe</span></div></body></html>`,
+			expected: StructuredData{
+				Entities: []StructuredEntity{{
+					Format:     FormatMicrodata,
+					Type:       "Product",
+					Properties: map[string]any{"name": "Shoe"},
+					Missing:    []string{"offers or review or aggregateRating"},
+				}},
+				Types:  map[string]int{"Product": 1},
+				Errors: []string{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, _ := html.Parse(strings.NewReader(tt.html))
+			result := getStructuredData(doc, baseURL)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, result)
+			}
+		})
+	}
+}
+
+// Table-driven tests for JSON-LD syntax errors
+func TestGetStructuredDataErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		expected []string
+	}{
+		{
+			name: "TrailingComma",
+			html: `<html><head><script type="application/ld+json">{"@type": "Organization",
+				"name": "Example",}</script></head></html>`,
+			expected: []string{"JSON-LD script 1: invalid JSON on line 2: invalid character '}' looking for beginning of object key string"},
+		},
+		{
+			name: "SecondScriptTruncated",
+			html: `<html><head>
+				<script type="application/ld+json">{"@type": "Organization"}</script>
+				<script type="application/ld+json">{"@type": </script>
+			</head></html>`,
+			expected: []string{"JSON-LD script 2: invalid JSON on line 1: unexpected end of JSON input"},
+		},
+	}
+
+	baseURL, _ := url.Parse("https://example.com/")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, _ := html.Parse(strings.NewReader(tt.html))
+			result := getStructuredData(doc, baseURL)
+			if !reflect.DeepEqual(result.Errors, tt.expected) {
+				t.Errorf("Expected %q, got %q", tt.expected, result.Errors)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package analyzer
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Kinds of references to third parties
+const (
+	ReferenceLink       = "link"
+	ReferenceScript     = "script"
+	ReferenceStylesheet = "stylesheet"
+	ReferenceImage      = "image"
+	ReferenceIframe     = "iframe"
+	ReferenceForm       = "form"
+	ReferenceMedia      = "media"
+	// ReferenceResource is a resource hint or icon <link>
+	ReferenceResource = "resource"
+)
+
+// ThirdPartyInventory lists the other sites the page links to or loads from
+type ThirdPartyInventory struct {
+	Domains []ThirdPartyDomain `json:"domains"`
+	// Trackers counts the domains classified as trackers
+	Trackers int `json:"trackers"`
+}
+
+// ThirdPartyDomain groups the references to the hosts of a registrable domain
+type ThirdPartyDomain struct {
+	// Domain is the registrable domain, e.g. example.co.uk
+	Domain string   `json:"domain"`
+	Hosts  []string `json:"hosts"`
+	// Kinds lists how the page references the domain, e.g. script or image
+	Kinds      []string `json:"kinds"`
+	References int      `json:"references"`
+	// Category and Company are set for domains matching a tracker rule
+	Category string `json:"category,omitempty"`
+	Company  string `json:"company,omitempty"`
+}
+
+// getThirdParties groups the hosts referenced by links, scripts, stylesheets, images, iframes,
+// forms and media of the document by registrable domain, leaving out the site of baseURL
+// and internalHosts, and classifies them with trackers
+func getThirdParties(doc *html.Node, baseURL *url.URL, internalHosts []string, trackers trackerMatcher) ThirdPartyInventory {
+	site := registrableDomain(baseURL.Hostname())
+	domains := make(map[string]*ThirdPartyDomain)
+
+	add := func(kind, ref string) {
+		u, err := url.Parse(strings.TrimSpace(ref))
+		if err != nil {
+			return
+		}
+		u = baseURL.ResolveReference(u)
+		host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+		if (u.Scheme != "http" && u.Scheme != "https") || host == "" {
+			return
+		}
+		domain := registrableDomain(host)
+		if domain == site || isInternalHost(host, internalHosts) {
+			return
+		}
+
+		d, ok := domains[domain]
+		if !ok {
+			d = &ThirdPartyDomain{Domain: domain, Hosts: []string{}, Kinds: []string{}}
+			domains[domain] = d
+		}
+		d.References++
+		if !slices.Contains(d.Hosts, host) {
+			d.Hosts = append(d.Hosts, host)
+		}
+		if !slices.Contains(d.Kinds, kind) {
+			d.Kinds = append(d.Kinds, kind)
+		}
+		if rule, ok := trackers.match(host); ok && d.Category == "" {
+			d.Category = rule.Category
+			d.Company = rule.Company
+		}
+	}
+	addAttr := func(n *html.Node, kind, key string) {
+		if val, ok := getAttr(n, key); ok {
+			add(kind, val)
+		}
+	}
+	addSrcset := func(n *html.Node) {
+		for _, c := range parseSrcset(attrValue(n, "srcset")) {
+			add(ReferenceImage, c.url)
+		}
+	}
+
+	var traverse func(*html.Node)
+	traverse = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.Data {
+			case "a", "area":
+				addAttr(n, ReferenceLink, "href")
+			case "script":
+				addAttr(n, ReferenceScript, "src")
+			case "link":
+				rel := strings.Fields(strings.ToLower(attrValue(n, "rel")))
+				switch {
+				case slices.Contains(rel, "stylesheet"):
+					addAttr(n, ReferenceStylesheet, "href")
+				case slices.ContainsFunc(rel, func(r string) bool { return slices.Contains(resourceHints, r) || r == "icon" }):
+					addAttr(n, ReferenceResource, "href")
+				}
+			case "img":
+				addAttr(n, ReferenceImage, "src")
+				addSrcset(n)
+			case "source":
+				if n.Parent != nil && n.Parent.Data == "picture" {
+					addSrcset(n)
+				} else {
+					addAttr(n, ReferenceMedia, "src")
+				}
+			case "input":
+				if strings.EqualFold(attrValue(n, "type"), "image") {
+					addAttr(n, ReferenceImage, "src")
+				}
+			case "iframe", "frame":
+				addAttr(n, ReferenceIframe, "src")
+			case "form":
+				addAttr(n, ReferenceForm, "action")
+			case "video", "audio", "embed", "track":
+				addAttr(n, ReferenceMedia, "src")
+				addAttr(n, ReferenceImage, "poster")
+			case "object":
+				addAttr(n, ReferenceMedia, "data")
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			traverse(c)
+		}
+	}
+	traverse(doc)
+
+	inventory := ThirdPartyInventory{Domains: []ThirdPartyDomain{}}
+	for _, d := range domains {
+		slices.Sort(d.Hosts)
+		inventory.Domains = append(inventory.Domains, *d)
+		if d.Category != "" {
+			inventory.Trackers++
+		}
+	}
+	slices.SortFunc(inventory.Domains, func(a, b ThirdPartyDomain) int { return strings.Compare(a.Domain, b.Domain) })
+	return inventory
+}
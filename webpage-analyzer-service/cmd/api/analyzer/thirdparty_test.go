@@ -0,0 +1,147 @@
+package analyzer
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// Table-driven tests for getThirdParties
+func TestGetThirdParties(t *testing.T) {
+	baseURL, _ := url.Parse("https://www.example.co.uk/shop/")
+	trackers := newTrackerMatcher(DefaultTrackerRules())
+	internalHosts := []string{"cdn.partner.net", "*.example-static.com"}
+
+	tests := []struct {
+		name     string
+		base     string
+		html     string
+		expected ThirdPartyInventory
+	}{
+		{
+			name: "FirstPartyOnly",
+			html: `<html><body>
+				<a href="/about">About</a><a href="https://blog.example.co.uk/">Blog</a>
+				<img src="//static.example.co.uk/logo.png"><a href="mailto:shop@example.co.uk">Mail</a>
+			</body></html>`,
+			expected: ThirdPartyInventory{Domains: []ThirdPartyDomain{}},
+		},
+		{
+			name: "GroupedByRegistrableDomain",
+			html: `<html><head>
+				<script src="https://www.googletagmanager.com/gtag/js?id=G-1"></script>
+				<link rel="stylesheet" href="https://fonts.googleapis.com/css2">
+				<link rel="preconnect" href="https://fonts.gstatic.com">
+			</head><body>
+				<img src="https://cdn.images.net/a.png" srcset="https://img2.images.net/a@2x.png 2x">
+				<iframe src="https://www.youtube.com/embed/1"></iframe>
+				<form action="https://forms.images.net/submit"></form>
+				<a href="https://www.youtube.com/watch?v=1">Video</a>
+			</body></html>`,
+			expected: ThirdPartyInventory{
+				Domains: []ThirdPartyDomain{
+					{Domain: "fonts.googleapis.com", Hosts: []string{"fonts.googleapis.com"}, Kinds: []string{ReferenceStylesheet}, References: 1},
+					{Domain: "googletagmanager.com", Hosts: []string{"www.googletagmanager.com"}, Kinds: []string{ReferenceScript}, References: 1, Category: TrackerAnalytics, Company: "Google"},
+					{Domain: "gstatic.com", Hosts: []string{"fonts.gstatic.com"}, Kinds: []string{ReferenceResource}, References: 1},
+					{Domain: "images.net", Hosts: []string{"cdn.images.net", "forms.images.net", "img2.images.net"}, Kinds: []string{ReferenceImage, ReferenceForm}, References: 3},
+					{Domain: "youtube.com", Hosts: []string{"www.youtube.com"}, Kinds: []string{ReferenceIframe, ReferenceLink}, References: 2},
+				},
+				Trackers: 1,
+			},
+		},
+		{
+			name: "TrackersMatchSubdomains",
+			html: `<html><body>
+				<script src="https://connect.facebook.net/en_US/fbevents.js"></script>
+				<img src="https://stats.g.doubleclick.net/pixel.gif">
+				<a href="https://www.facebook.com/example">Facebook</a>
+			</body></html>`,
+			expected: ThirdPartyInventory{
+				Domains: []ThirdPartyDomain{
+					{Domain: "doubleclick.net", Hosts: []string{"stats.g.doubleclick.net"}, Kinds: []string{ReferenceImage}, References: 1, Category: TrackerAdvertising, Company: "Google"},
+					{Domain: "facebook.com", Hosts: []string{"www.facebook.com"}, Kinds: []string{ReferenceLink}, References: 1},
+					{Domain: "facebook.net", Hosts: []string{"connect.facebook.net"}, Kinds: []string{ReferenceScript}, References: 1, Category: TrackerSocial, Company: "Meta"},
+				},
+				Trackers: 2,
+			},
+		},
+		{
+			name: "InternalHosts",
+			html: `<html><body>
+				<script src="https://cdn.partner.net/app.js"></script>
+				<img src="https://img.example-static.com/a.png">
+				<a href="https://www.partner.net/">Partner</a>
+			</body></html>`,
+			expected: ThirdPartyInventory{
+				Domains: []ThirdPartyDomain{
+					{Domain: "partner.net", Hosts: []string{"www.partner.net"}, Kinds: []string{ReferenceLink}, References: 1},
+				},
+			},
+		},
+		{
+			name: "IPv4Addresses",
+			base: "http://127.0.0.1:8080/",
+			html: `<html><body>
+				<img src="http://127.0.0.1:8080/logo.png"><script src="http://10.0.0.1/app.js"></script>
+			</body></html>`,
+			expected: ThirdPartyInventory{
+				Domains: []ThirdPartyDomain{
+					{Domain: "10.0.0.1", Hosts: []string{"10.0.0.1"}, Kinds: []string{ReferenceScript}, References: 1},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := baseURL
+			if tt.base != "" {
+				base, _ = url.Parse(tt.base)
+			}
+			doc, err := parseHTML(tt.html)
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+			if got := getThirdParties(doc, base, internalHosts, trackers); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("getThirdParties() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+// Table-driven tests for ParseTrackerRules
+func TestParseTrackerRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected []TrackerRule
+		wantErr  bool
+	}{
+		{
+			name:     "Valid",
+			data:     `[{"domain": "Tracker.Example.", "category": "analytics", "company": "Example"}]`,
+			expected: []TrackerRule{{Domain: "tracker.example", Category: TrackerAnalytics, Company: "Example"}},
+		},
+		{name: "InvalidJSON", data: `[{"domain": }]`, wantErr: true},
+		{name: "MissingDomain", data: `[{"category": "social"}]`, wantErr: true},
+		{name: "UnknownCategory", data: `[{"domain": "a.example", "category": "fingerprinting"}]`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := ParseTrackerRules([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTrackerRules() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(rules, tt.expected) {
+				t.Errorf("ParseTrackerRules() = %+v, want %+v", rules, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDefaultTrackerRules(t *testing.T) {
+	if rules := DefaultTrackerRules(); len(rules) == 0 {
+		t.Error("DefaultTrackerRules() returned no rules")
+	}
+}
@@ -0,0 +1,82 @@
+package analyzer
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Categories of trackers
+const (
+	TrackerAnalytics   = "analytics"
+	TrackerAdvertising = "advertising"
+	TrackerSocial      = "social"
+)
+
+// TrackerRule classifies a domain and its subdomains as a tracker
+type TrackerRule struct {
+	Domain   string `json:"domain"`
+	Category string `json:"category"`
+	Company  string `json:"company"`
+}
+
+// defaultTrackerRules is the rule list used unless Options.TrackerRules replaces it
+//
+//go:embed trackers.json
+var defaultTrackerRules []byte
+
+// DefaultTrackerRules returns the embedded tracker rule list
+func DefaultTrackerRules() []TrackerRule {
+	rules, err := ParseTrackerRules(defaultTrackerRules)
+	if err != nil {
+		panic(fmt.Sprintf("embedded tracker rules: %v", err))
+	}
+	return rules
+}
+
+// ParseTrackerRules parses a JSON list of tracker rules like the embedded trackers.json
+func ParseTrackerRules(data []byte) ([]TrackerRule, error) {
+	var rules []TrackerRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, err
+	}
+	for i, rule := range rules {
+		if rule.Domain == "" {
+			return nil, fmt.Errorf("rule %d has no domain", i)
+		}
+		switch rule.Category {
+		case TrackerAnalytics, TrackerAdvertising, TrackerSocial:
+		default:
+			return nil, fmt.Errorf("rule %d for %s has unknown category %q", i, rule.Domain, rule.Category)
+		}
+		rules[i].Domain = strings.TrimSuffix(strings.ToLower(rule.Domain), ".")
+	}
+	return rules, nil
+}
+
+// trackerMatcher looks up the rule of a host
+type trackerMatcher map[string]TrackerRule
+
+func newTrackerMatcher(rules []TrackerRule) trackerMatcher {
+	m := make(trackerMatcher, len(rules))
+	for _, rule := range rules {
+		m[rule.Domain] = rule
+	}
+	return m
+}
+
+// match returns the rule of host or of the closest parent domain with a rule
+func (m trackerMatcher) match(host string) (TrackerRule, bool) {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for {
+		if rule, ok := m[host]; ok {
+			return rule, true
+		}
+		_, parent, ok := strings.Cut(host, ".")
+		if !ok {
+			return TrackerRule{}, false
+		}
+		host = parent
+	}
+}
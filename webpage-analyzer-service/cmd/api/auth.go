@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"webpage-analyzer/cmd/api/analyzer"
+)
+
+// apiKeyHeader carries the API key; "Authorization: Bearer <key>" is accepted as well
+const apiKeyHeader = "X-API-Key"
+
+type apiKeyContextKey struct{}
+
+// authenticate rejects requests without a valid API key and stores the key in the
+// request context. It lets every request through when auth is disabled.
+func (app *Config) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !app.Auth.Enabled {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key := apiKeyFromRequest(r)
+		if key == "" {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			app.errorJSON(w, errors.New("missing API key"), http.StatusUnauthorized)
+			return
+		}
+
+		sum := sha256.Sum256([]byte(key))
+		apiKey, ok := app.apiKeys[hex.EncodeToString(sum[:])]
+		if !ok {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			app.errorJSON(w, errors.New("invalid API key"), http.StatusUnauthorized)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), apiKeyContextKey{}, apiKey)
+		if apiKey.LinkChecksPerAnalysis > 0 {
+			ctx = analyzer.WithLinkCheckLimit(ctx, apiKey.LinkChecksPerAnalysis)
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// takeQuota uses one analysis of the daily quota of the API key that authenticated r.
+// runAnalysis calls it once the request is valid and not served from the cache, so that
+// rejected requests and cached results do not use up the quota. When the quota is spent
+// it writes the error response itself and reports false.
+func (app *Config) takeQuota(w http.ResponseWriter, r *http.Request) bool {
+	apiKey, ok := apiKeyFromContext(r.Context())
+	if !ok {
+		return true
+	}
+
+	if ok, retryAfter := app.quotas.take(apiKey.Name, apiKey.AnalysesPerDay); !ok {
+		w.Header().Set("Retry-After", retryAfterSeconds(retryAfter))
+		app.errorJSON(w, errors.New("daily analysis quota exceeded"), http.StatusTooManyRequests)
+		return false
+	}
+
+	return true
+}
+
+// apiKeyFromRequest returns the API key sent in the X-API-Key or Authorization header
+func apiKeyFromRequest(r *http.Request) string {
+	if key := r.Header.Get(apiKeyHeader); key != "" {
+		return key
+	}
+
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+
+	return ""
+}
+
+// apiKeyFromContext returns the API key that authenticated the request, if any
+func apiKeyFromContext(ctx context.Context) (APIKeyConfig, bool) {
+	apiKey, ok := ctx.Value(apiKeyContextKey{}).(APIKeyConfig)
+	return apiKey, ok
+}
+
+// quotaTracker counts how many analyses every API key ran during the current UTC day
+type quotaTracker struct {
+	mu   sync.Mutex
+	day  time.Time
+	used map[string]int
+	now  func() time.Time
+}
+
+func newQuotaTracker() *quotaTracker {
+	return &quotaTracker{
+		used: make(map[string]int),
+		now:  time.Now,
+	}
+}
+
+// take uses one analysis of the daily limit of name. When the limit is reached it
+// reports false and how long until the quota resets. A limit of zero means unlimited.
+func (q *quotaTracker) take(name string, limit int) (bool, time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now().UTC()
+	today := now.Truncate(24 * time.Hour)
+	if !today.Equal(q.day) {
+		q.day = today
+		q.used = make(map[string]int)
+	}
+
+	if limit > 0 && q.used[name] >= limit {
+		return false, today.Add(24 * time.Hour).Sub(now)
+	}
+
+	q.used[name]++
+	return true, 0
+}
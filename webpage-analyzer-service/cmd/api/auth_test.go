@@ -0,0 +1,298 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"webpage-analyzer/cmd/api/analyzer"
+	"webpage-analyzer/internal/ratelimit"
+
+	"golang.org/x/time/rate"
+)
+
+// hashKey returns the SHA-256 hex digest stored for key
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// newAuthTestApp returns an app with auth enabled and a stub analyzer
+func newAuthTestApp(t *testing.T, keys ...APIKeyConfig) *Config {
+	t.Helper()
+
+	app := defaultConfig()
+	app.Auth.Enabled = true
+	app.Auth.Keys = keys
+	if err := app.setup(); err != nil {
+		t.Fatalf("setup() error = %v", err)
+	}
+	app.analyze = func(ctx context.Context, urlStr string) (analyzer.AnalysisResult, error) {
+		return analyzer.AnalysisResult{PageTitle: urlStr}, nil
+	}
+	return app
+}
+
+func analyzeRequest(headers map[string]string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"url": "https://www.example.com"}`))
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	return req
+}
+
+// Table-driven tests for authenticate
+func TestAuthenticate(t *testing.T) {
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		expected int
+	}{
+		{
+			name:     "MissingKey",
+			expected: http.StatusUnauthorized,
+		},
+		{
+			name:     "InvalidKey",
+			headers:  map[string]string{"X-API-Key": "wrong"},
+			expected: http.StatusUnauthorized,
+		},
+		{
+			name:     "APIKeyHeader",
+			headers:  map[string]string{"X-API-Key": "secret"},
+			expected: http.StatusOK,
+		},
+		{
+			name:     "BearerToken",
+			headers:  map[string]string{"Authorization": "Bearer secret"},
+			expected: http.StatusOK,
+		},
+		{
+			name:     "BasicAuthIgnored",
+			headers:  map[string]string{"Authorization": "Basic secret"},
+			expected: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newAuthTestApp(t, APIKeyConfig{Name: "frontend", Hash: hashKey("secret")})
+
+			rr := httptest.NewRecorder()
+			app.routes().ServeHTTP(rr, analyzeRequest(tt.headers))
+
+			if rr.Code != tt.expected {
+				t.Errorf("POST / = %v, want %v", rr.Code, tt.expected)
+			}
+			if !strings.Contains(rr.Body.String(), `"error":`) {
+				t.Errorf("POST / body = %s, want a JSON response", rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthenticateDisabled(t *testing.T) {
+	app := newAuthTestApp(t, APIKeyConfig{Name: "frontend", Hash: hashKey("secret")})
+	app.Auth.Enabled = false
+
+	rr := httptest.NewRecorder()
+	app.routes().ServeHTTP(rr, analyzeRequest(nil))
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("POST / = %v, want %v", rr.Code, http.StatusOK)
+	}
+}
+
+func TestAuthenticateDailyQuota(t *testing.T) {
+	app := newAuthTestApp(t, APIKeyConfig{Name: "frontend", Hash: hashKey("secret"), AnalysesPerDay: 2})
+	app.quotas.now = func() time.Time {
+		return time.Date(2024, 6, 1, 23, 0, 0, 0, time.UTC)
+	}
+
+	for i := 0; i < 2; i++ {
+		rr := httptest.NewRecorder()
+		app.routes().ServeHTTP(rr, analyzeRequest(map[string]string{"X-API-Key": "secret"}))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("analysis %d = %v, want %v", i+1, rr.Code, http.StatusOK)
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	app.routes().ServeHTTP(rr, analyzeRequest(map[string]string{"X-API-Key": "secret"}))
+	if rr.Code != http.StatusTooManyRequests {
+		t.Errorf("analysis over quota = %v, want %v", rr.Code, http.StatusTooManyRequests)
+	}
+	if got := rr.Header().Get("Retry-After"); got != "3600" {
+		t.Errorf("Retry-After = %q, want %q", got, "3600")
+	}
+
+	// the quota resets on the next UTC day
+	app.quotas.now = func() time.Time {
+		return time.Date(2024, 6, 2, 0, 0, 1, 0, time.UTC)
+	}
+	rr = httptest.NewRecorder()
+	app.routes().ServeHTTP(rr, analyzeRequest(map[string]string{"X-API-Key": "secret"}))
+	if rr.Code != http.StatusOK {
+		t.Errorf("analysis on the next day = %v, want %v", rr.Code, http.StatusOK)
+	}
+}
+
+func TestRateLimitedRequestsKeepQuota(t *testing.T) {
+	app := newAuthTestApp(t, APIKeyConfig{Name: "frontend", Hash: hashKey("secret"), AnalysesPerDay: 3})
+	app.clients = ratelimit.New(rate.Every(time.Hour), 1)
+	routes := app.routes()
+
+	expected := []int{http.StatusOK, http.StatusTooManyRequests, http.StatusTooManyRequests}
+	for i, want := range expected {
+		rr := httptest.NewRecorder()
+		routes.ServeHTTP(rr, analyzeRequest(map[string]string{"X-API-Key": "secret"}))
+		if rr.Code != want {
+			t.Fatalf("analysis %d = %v, want %v", i+1, rr.Code, want)
+		}
+	}
+
+	if used := app.quotas.used["frontend"]; used != 1 {
+		t.Errorf("quota used = %v, want 1", used)
+	}
+}
+
+// Table-driven tests for the requests that do not use the daily quota
+func TestRejectedRequestsKeepQuota(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		body     string
+		expected int
+	}{
+		{
+			name:     "MalformedJSON",
+			target:   "/v1/analyze",
+			body:     `{"url": `,
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "UnknownField",
+			target:   "/v1/analyze",
+			body:     `{"url": "https://www.example.com", "depth": 2}`,
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "InvalidURL",
+			target:   "/",
+			body:     `{"url": "not a url"}`,
+			expected: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newAuthTestApp(t, APIKeyConfig{Name: "frontend", Hash: hashKey("secret"), AnalysesPerDay: 1})
+
+			req := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body))
+			req.Header.Set("X-API-Key", "secret")
+			rr := httptest.NewRecorder()
+			app.routes().ServeHTTP(rr, req)
+
+			if rr.Code != tt.expected {
+				t.Errorf("POST %s = %v, want %v", tt.target, rr.Code, tt.expected)
+			}
+			if used := app.quotas.used["frontend"]; used != 0 {
+				t.Errorf("quota used = %v, want 0", used)
+			}
+		})
+	}
+}
+
+func TestCachedResultsKeepQuota(t *testing.T) {
+	app := newAuthTestApp(t, APIKeyConfig{Name: "frontend", Hash: hashKey("secret"), AnalysesPerDay: 1})
+	app.cache = newResultCache(time.Minute, 10)
+	routes := app.routes()
+
+	for i := 0; i < 3; i++ {
+		rr := httptest.NewRecorder()
+		routes.ServeHTTP(rr, analyzeRequest(map[string]string{"X-API-Key": "secret"}))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("analysis %d = %v, want %v", i+1, rr.Code, http.StatusOK)
+		}
+	}
+
+	if used := app.quotas.used["frontend"]; used != 1 {
+		t.Errorf("quota used = %v, want 1", used)
+	}
+}
+
+func TestAuthenticateLinkCheckQuota(t *testing.T) {
+	app := newAuthTestApp(t, APIKeyConfig{Name: "frontend", Hash: hashKey("secret"), LinkChecksPerAnalysis: 25})
+
+	var got int
+	app.analyze = func(ctx context.Context, urlStr string) (analyzer.AnalysisResult, error) {
+		got = analyzer.LinkCheckLimit(ctx)
+		return analyzer.AnalysisResult{}, nil
+	}
+
+	rr := httptest.NewRecorder()
+	app.routes().ServeHTTP(rr, analyzeRequest(map[string]string{"X-API-Key": "secret"}))
+
+	if got != 25 {
+		t.Errorf("link check limit = %v, want 25", got)
+	}
+}
+
+func TestCacheSeparatesLinkCheckQuotas(t *testing.T) {
+	app := newAuthTestApp(t,
+		APIKeyConfig{Name: "limited", Hash: hashKey("limited"), LinkChecksPerAnalysis: 10},
+		APIKeyConfig{Name: "unlimited", Hash: hashKey("unlimited")},
+	)
+	app.cache = newResultCache(time.Minute, 10)
+
+	var limits []int
+	app.analyze = func(ctx context.Context, urlStr string) (analyzer.AnalysisResult, error) {
+		limits = append(limits, analyzer.LinkCheckLimit(ctx))
+		return analyzer.AnalysisResult{}, nil
+	}
+
+	for _, key := range []string{"unlimited", "limited", "limited", "unlimited"} {
+		rr := httptest.NewRecorder()
+		app.routes().ServeHTTP(rr, analyzeRequest(map[string]string{"X-API-Key": key}))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("analysis with %s key = %v, want %v", key, rr.Code, http.StatusOK)
+		}
+	}
+
+	// every quota is analyzed once, the repeated requests are served from the cache
+	if !reflect.DeepEqual(limits, []int{0, 10}) {
+		t.Errorf("analyzed with link check limits %v, want [0 10]", limits)
+	}
+}
+
+func TestLoadAPIKeysFromStorage(t *testing.T) {
+	dir := t.TempDir()
+	stored := `[{"name": "reporting", "hash": "` + hashKey("stored-secret") + `", "analysesPerDay": 100}]`
+	if err := os.WriteFile(filepath.Join(dir, apiKeysFile), []byte(stored), 0o600); err != nil {
+		t.Fatalf("Failed to write API keys: %v", err)
+	}
+
+	app := defaultConfig()
+	app.Auth.Enabled = true
+	app.Storage.Dir = dir
+	if err := app.setup(); err != nil {
+		t.Fatalf("setup() error = %v", err)
+	}
+	app.analyze = func(ctx context.Context, urlStr string) (analyzer.AnalysisResult, error) {
+		return analyzer.AnalysisResult{}, nil
+	}
+
+	rr := httptest.NewRecorder()
+	app.routes().ServeHTTP(rr, analyzeRequest(map[string]string{"X-API-Key": "stored-secret"}))
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("POST / with stored key = %v, want %v", rr.Code, http.StatusOK)
+	}
+}
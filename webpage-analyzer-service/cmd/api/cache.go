@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+	"webpage-analyzer/cmd/api/analyzer"
+)
+
+// resultCache keeps analysis results in memory for a limited time
+type resultCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	result  analyzer.AnalysisResult
+	expires time.Time
+}
+
+func newResultCache(ttl time.Duration, maxEntries int) *resultCache {
+	return &resultCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]cacheEntry),
+	}
+}
+
+// resultCacheKey returns the cache key of an analysis of url. Results depend on the link
+// check limit of the API key, so limited analyses are cached apart from unlimited ones.
+func resultCacheKey(ctx context.Context, url string) string {
+	if limit := analyzer.LinkCheckLimit(ctx); limit > 0 {
+		// a space never appears in a parsed URL, so the key cannot collide with another URL
+		return fmt.Sprintf("%d %s", limit, url)
+	}
+	return url
+}
+
+// get returns the cached result for url if it has not expired
+func (c *resultCache) get(url string) (analyzer.AnalysisResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[url]
+	if !ok {
+		return analyzer.AnalysisResult{}, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(c.entries, url)
+		return analyzer.AnalysisResult{}, false
+	}
+	return entry.result, true
+}
+
+// set stores the result for url, evicting the entry closest to expiry when the cache is full
+func (c *resultCache) set(url string, result analyzer.AnalysisResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.entries[url]; !ok && len(c.entries) >= c.maxEntries {
+		var oldest string
+		for key, entry := range c.entries {
+			if oldest == "" || entry.expires.Before(c.entries[oldest].expires) {
+				oldest = key
+			}
+		}
+		delete(c.entries, oldest)
+	}
+
+	c.entries[url] = cacheEntry{result: result, expires: time.Now().Add(c.ttl)}
+}
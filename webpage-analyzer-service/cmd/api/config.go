@@ -0,0 +1,671 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+	"webpage-analyzer/api"
+	"webpage-analyzer/cmd/api/analyzer"
+	"webpage-analyzer/internal/ratelimit"
+
+	"golang.org/x/time/rate"
+	"gopkg.in/yaml.v3"
+)
+
+// envPrefix prefixes the environment variable of every setting, e.g. ANALYZER_PORT
+const envPrefix = "ANALYZER_"
+
+// Config holds the service settings together with the services built from them.
+// Settings are read from defaults, then a YAML or JSON file, then environment
+// variables and finally command line flags, each overriding the previous one.
+type Config struct {
+	Port     string         `yaml:"port"`
+	Server   ServerConfig   `yaml:"server"`
+	Analysis AnalysisConfig `yaml:"analysis"`
+	CORS     CORSConfig     `yaml:"cors"`
+	Cache    CacheConfig    `yaml:"cache"`
+	Storage  StorageConfig  `yaml:"storage"`
+	Auth     AuthConfig     `yaml:"auth"`
+	// RateLimit limits how often a single client may request analyses
+	RateLimit RateLimitConfig `yaml:"rateLimit"`
+
+	// ready reports whether the service accepts new analyses
+	ready atomic.Bool
+	// analyze runs a single analysis
+	analyze analyzer.AnalyzeURLFunc
+	// slots limits the number of analyses running at the same time
+	slots chan struct{}
+	// cache keeps recent analysis results, nil when caching is disabled
+	cache *resultCache
+	// apiKeys maps the SHA-256 hex digest of every API key to its settings
+	apiKeys map[string]APIKeyConfig
+	// quotas counts the analyses every API key ran today
+	quotas *quotaTracker
+	// clients rate limits analyses per API key or client IP, nil when unlimited
+	clients *ratelimit.Limiters
+	// specJSON is the OpenAPI spec converted to JSON
+	specJSON []byte
+}
+
+// ServerConfig configures the HTTP server
+type ServerConfig struct {
+	ReadHeaderTimeout time.Duration `yaml:"readHeaderTimeout"`
+	ReadTimeout       time.Duration `yaml:"readTimeout"`
+	// analyses check every link on the page, so responses can take a while
+	WriteTimeout time.Duration `yaml:"writeTimeout"`
+	IdleTimeout  time.Duration `yaml:"idleTimeout"`
+	// ShutdownDelay is how long the service keeps serving after /ready turns unavailable,
+	// so load balancers stop sending traffic before the listeners close
+	ShutdownDelay time.Duration `yaml:"shutdownDelay"`
+	// ShutdownTimeout is how long running analyses get to finish on shutdown
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout"`
+	// MaxConcurrentAnalyses is the number of analyses allowed to run at the same time
+	MaxConcurrentAnalyses int `yaml:"maxConcurrentAnalyses"`
+}
+
+// AnalysisConfig configures how pages are fetched and links are checked
+type AnalysisConfig struct {
+	UserAgent string `yaml:"userAgent"`
+	// Timeout bounds a whole analysis including the wait for a free slot, so the
+	// response is written before server.writeTimeout
+	Timeout          time.Duration `yaml:"timeout"`
+	FetchTimeout     time.Duration `yaml:"fetchTimeout"`
+	LinkCheckTimeout time.Duration `yaml:"linkCheckTimeout"`
+	MaxLinkChecks    int           `yaml:"maxLinkChecks"`
+	// HostRate limits the requests per second sent to a single target host, zero disables the limit
+	HostRate    float64       `yaml:"hostRate"`
+	HostBurst   int           `yaml:"hostBurst"`
+	HostMaxWait time.Duration `yaml:"hostMaxWait"`
+	// MaxDocumentSize limits the page body in bytes as transferred, MaxDecompressedSize after decompression
+	MaxDocumentSize     int64 `yaml:"maxDocumentSize"`
+	MaxDecompressedSize int64 `yaml:"maxDecompressedSize"`
+	// MaxProbeBytes limits the bytes that probing the images, scripts and stylesheets of one page downloads
+	MaxProbeBytes int64 `yaml:"maxProbeBytes"`
+	// TrackerRules is a JSON file replacing the embedded tracker rule list
+	TrackerRules string `yaml:"trackerRules"`
+	// InternalHosts lists hosts whose links count as internal besides the page's own site
+	InternalHosts []string `yaml:"internalHosts"`
+}
+
+// RateLimitConfig configures the token bucket every client gets
+type RateLimitConfig struct {
+	// Rate is the number of analyses per second a client may request, zero disables the limit
+	Rate  float64 `yaml:"rate"`
+	Burst int     `yaml:"burst"`
+}
+
+// CORSConfig configures which browser origins may call the service and how
+type CORSConfig struct {
+	// AllowedOrigins lists origins such as http://localhost; a single * wildcard is allowed in the host
+	AllowedOrigins   []string      `yaml:"allowedOrigins"`
+	AllowedMethods   []string      `yaml:"allowedMethods"`
+	AllowedHeaders   []string      `yaml:"allowedHeaders"`
+	AllowCredentials bool          `yaml:"allowCredentials"`
+	MaxAge           time.Duration `yaml:"maxAge"`
+}
+
+// CacheConfig configures the in-memory cache of analysis results
+type CacheConfig struct {
+	Enabled    bool          `yaml:"enabled"`
+	TTL        time.Duration `yaml:"ttl"`
+	MaxEntries int           `yaml:"maxEntries"`
+}
+
+// StorageConfig configures where the service keeps persistent data
+type StorageConfig struct {
+	Dir string `yaml:"dir"`
+}
+
+// AuthConfig configures API key authentication
+type AuthConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Keys adds to the keys read from the storage directory
+	Keys []APIKeyConfig `yaml:"keys"`
+}
+
+// APIKeyConfig describes an API key and its quotas. Keys are stored as the
+// SHA-256 hex digest of the key so plain keys are never kept at rest.
+type APIKeyConfig struct {
+	Name string `yaml:"name"`
+	Hash string `yaml:"hash"`
+	// AnalysesPerDay limits the analyses per UTC day, zero means unlimited
+	AnalysesPerDay int `yaml:"analysesPerDay"`
+	// LinkChecksPerAnalysis limits the links checked in one analysis, zero means unlimited
+	LinkChecksPerAnalysis int `yaml:"linkChecksPerAnalysis"`
+}
+
+// apiKeysFile is the file in the storage directory that holds API keys
+const apiKeysFile = "api_keys.json"
+
+// defaultConfig returns the settings used when nothing else is configured
+func defaultConfig() *Config {
+	opts := analyzer.DefaultOptions()
+
+	return &Config{
+		Port: "80",
+		Server: ServerConfig{
+			ReadHeaderTimeout:     5 * time.Second,
+			ReadTimeout:           10 * time.Second,
+			WriteTimeout:          2 * time.Minute,
+			IdleTimeout:           60 * time.Second,
+			ShutdownDelay:         5 * time.Second,
+			ShutdownTimeout:       30 * time.Second,
+			MaxConcurrentAnalyses: 10,
+		},
+		Analysis: AnalysisConfig{
+			UserAgent:           opts.UserAgent,
+			Timeout:             100 * time.Second,
+			FetchTimeout:        opts.FetchTimeout,
+			LinkCheckTimeout:    opts.LinkCheckTimeout,
+			MaxLinkChecks:       opts.MaxLinkChecks,
+			HostRate:            opts.HostRate,
+			HostBurst:           opts.HostBurst,
+			HostMaxWait:         opts.HostMaxWait,
+			MaxDocumentSize:     opts.MaxDocumentSize,
+			MaxDecompressedSize: opts.MaxDecompressedSize,
+			MaxProbeBytes:       opts.MaxProbeBytes,
+		},
+		RateLimit: RateLimitConfig{
+			Rate:  1,
+			Burst: 5,
+		},
+		// only the frontend is allowed by default
+		CORS: CORSConfig{
+			AllowedOrigins: []string{"http://localhost"},
+			AllowedMethods: []string{http.MethodGet, http.MethodPost, http.MethodOptions},
+			AllowedHeaders: []string{"Accept", "Authorization", "Content-Type", "X-API-Key"},
+			MaxAge:         5 * time.Minute,
+		},
+		Cache: CacheConfig{
+			Enabled:    false,
+			TTL:        5 * time.Minute,
+			MaxEntries: 100,
+		},
+	}
+}
+
+// setting binds a flag and its environment variable to a Config field
+type setting struct {
+	name  string
+	usage string
+	set   func(string) error
+}
+
+// env returns the environment variable of the setting, e.g. ANALYZER_READ_TIMEOUT for read-timeout
+func (s setting) env() string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(s.name, "-", "_"))
+}
+
+func (app *Config) settings() []setting {
+	return []setting{
+		{"port", "port to listen on", stringSetting(&app.Port)},
+		{"read-header-timeout", "time allowed to read request headers", durationSetting(&app.Server.ReadHeaderTimeout)},
+		{"read-timeout", "time allowed to read a request", durationSetting(&app.Server.ReadTimeout)},
+		{"write-timeout", "time allowed to write a response", durationSetting(&app.Server.WriteTimeout)},
+		{"idle-timeout", "time to keep idle connections open", durationSetting(&app.Server.IdleTimeout)},
+		{"shutdown-delay", "time to keep serving after readiness turns unavailable on shutdown", durationSetting(&app.Server.ShutdownDelay)},
+		{"shutdown-timeout", "time running analyses get to finish on shutdown", durationSetting(&app.Server.ShutdownTimeout)},
+		{"max-concurrent-analyses", "number of analyses allowed to run at the same time", intSetting(&app.Server.MaxConcurrentAnalyses)},
+		{"user-agent", "user agent sent to analyzed sites", stringSetting(&app.Analysis.UserAgent)},
+		{"analysis-timeout", "time allowed for a whole analysis, shorter than the write timeout", durationSetting(&app.Analysis.Timeout)},
+		{"fetch-timeout", "time allowed to fetch the analyzed page", durationSetting(&app.Analysis.FetchTimeout)},
+		{"link-check-timeout", "time allowed to check a single link", durationSetting(&app.Analysis.LinkCheckTimeout)},
+		{"max-link-checks", "number of links, images, scripts and stylesheets one analysis checks at the same time", intSetting(&app.Analysis.MaxLinkChecks)},
+		{"host-rate", "requests per second sent to a single target host, 0 for unlimited", floatSetting(&app.Analysis.HostRate)},
+		{"host-burst", "requests sent to a single target host at once", intSetting(&app.Analysis.HostBurst)},
+		{"host-max-wait", "time a page fetch may wait for its host's rate limit", durationSetting(&app.Analysis.HostMaxWait)},
+		{"max-document-size", "largest page body accepted in bytes, as transferred", int64Setting(&app.Analysis.MaxDocumentSize)},
+		{"max-decompressed-size", "largest page accepted in bytes, after decompression", int64Setting(&app.Analysis.MaxDecompressedSize)},
+		{"max-probe-bytes", "bytes that probing the resources of one page may download", int64Setting(&app.Analysis.MaxProbeBytes)},
+		{"tracker-rules", "JSON file replacing the embedded tracker rule list", stringSetting(&app.Analysis.TrackerRules)},
+		{"internal-hosts", "comma separated hosts whose links count as internal, with their subdomains", listSetting(&app.Analysis.InternalHosts)},
+		{"rate-limit", "analyses per second a single client may request, 0 for unlimited", floatSetting(&app.RateLimit.Rate)},
+		{"rate-limit-burst", "analyses a single client may request at once", intSetting(&app.RateLimit.Burst)},
+		{"cors-allowed-origins", "comma separated origins allowed to call the service", listSetting(&app.CORS.AllowedOrigins)},
+		{"cors-allowed-methods", "comma separated methods allowed in cross-origin requests", listSetting(&app.CORS.AllowedMethods)},
+		{"cors-allowed-headers", "comma separated headers allowed in cross-origin requests", listSetting(&app.CORS.AllowedHeaders)},
+		{"cors-allow-credentials", "allow cross-origin requests with credentials", boolSetting(&app.CORS.AllowCredentials)},
+		{"cors-max-age", "time browsers may cache preflight responses", durationSetting(&app.CORS.MaxAge)},
+		{"cache-enabled", "cache analysis results", boolSetting(&app.Cache.Enabled)},
+		{"cache-ttl", "time analysis results stay cached", durationSetting(&app.Cache.TTL)},
+		{"cache-max-entries", "number of analysis results kept in the cache", intSetting(&app.Cache.MaxEntries)},
+		{"storage-dir", "directory for persistent data", stringSetting(&app.Storage.Dir)},
+		{"auth-enabled", "require an API key for analyses", boolSetting(&app.Auth.Enabled)},
+	}
+}
+
+func stringSetting(p *string) func(string) error {
+	return func(v string) error {
+		*p = v
+		return nil
+	}
+}
+
+func durationSetting(p *time.Duration) func(string) error {
+	return func(v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		*p = d
+		return nil
+	}
+}
+
+func intSetting(p *int) func(string) error {
+	return func(v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return err
+		}
+		*p = n
+		return nil
+	}
+}
+
+func int64Setting(p *int64) func(string) error {
+	return func(v string) error {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return err
+		}
+		*p = n
+		return nil
+	}
+}
+
+func floatSetting(p *float64) func(string) error {
+	return func(v string) error {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return err
+		}
+		*p = f
+		return nil
+	}
+}
+
+func boolSetting(p *bool) func(string) error {
+	return func(v string) error {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return err
+		}
+		*p = b
+		return nil
+	}
+}
+
+func listSetting(p *[]string) func(string) error {
+	return func(v string) error {
+		var list []string
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		*p = list
+		return nil
+	}
+}
+
+// loadConfig builds the configuration from the config file, the environment and args.
+// The config file is given by the -config flag or the ANALYZER_CONFIG variable.
+func loadConfig(args []string, getenv func(string) string) (*Config, error) {
+	app := defaultConfig()
+	settings := app.settings()
+
+	// flags are only recorded here and applied last, so they win over the file and the environment
+	type flagValue struct {
+		s     setting
+		value string
+	}
+	var flagValues []flagValue
+
+	fs := flag.NewFlagSet("analyzer", flag.ContinueOnError)
+	configPath := fs.String("config", getenv(envPrefix+"CONFIG"), "path to a YAML or JSON config file")
+	for _, s := range settings {
+		fs.Func(s.name, fmt.Sprintf("%s (env %s)", s.usage, s.env()), func(v string) error {
+			flagValues = append(flagValues, flagValue{s, v})
+			return nil
+		})
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *configPath != "" {
+		if err := app.readFile(*configPath); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, s := range settings {
+		v := getenv(s.env())
+		if v == "" {
+			continue
+		}
+		if err := s.set(v); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", s.env(), err)
+		}
+	}
+
+	for _, f := range flagValues {
+		if err := f.s.set(f.value); err != nil {
+			return nil, fmt.Errorf("invalid -%s: %w", f.s.name, err)
+		}
+	}
+
+	if err := app.validate(); err != nil {
+		return nil, err
+	}
+
+	return app, nil
+}
+
+// readFile reads settings from a YAML or JSON file, rejecting unknown keys
+func (app *Config) readFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+	defer f.Close()
+
+	// JSON is valid YAML, so one decoder handles both formats
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(app); err != nil {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// validate reports every invalid setting at once
+func (app *Config) validate() error {
+	var errs []error
+
+	if port, err := strconv.Atoi(app.Port); err != nil || port < 1 || port > 65535 {
+		errs = append(errs, fmt.Errorf("port must be a number between 1 and 65535, got %q", app.Port))
+	}
+
+	durations := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"server.readHeaderTimeout", app.Server.ReadHeaderTimeout},
+		{"server.readTimeout", app.Server.ReadTimeout},
+		{"server.writeTimeout", app.Server.WriteTimeout},
+		{"server.idleTimeout", app.Server.IdleTimeout},
+		{"server.shutdownTimeout", app.Server.ShutdownTimeout},
+		{"analysis.timeout", app.Analysis.Timeout},
+		{"analysis.fetchTimeout", app.Analysis.FetchTimeout},
+		{"analysis.linkCheckTimeout", app.Analysis.LinkCheckTimeout},
+	}
+	for _, d := range durations {
+		if d.value <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive, got %s", d.name, d.value))
+		}
+	}
+	if app.Server.ShutdownDelay < 0 {
+		errs = append(errs, fmt.Errorf("server.shutdownDelay must not be negative, got %s", app.Server.ShutdownDelay))
+	}
+	if app.Analysis.Timeout >= app.Server.WriteTimeout {
+		errs = append(errs, fmt.Errorf("analysis.timeout (%s) must be shorter than server.writeTimeout (%s)", app.Analysis.Timeout, app.Server.WriteTimeout))
+	}
+	if app.Analysis.FetchTimeout > app.Server.WriteTimeout {
+		errs = append(errs, fmt.Errorf("analysis.fetchTimeout (%s) must not exceed server.writeTimeout (%s)", app.Analysis.FetchTimeout, app.Server.WriteTimeout))
+	}
+
+	if app.Server.MaxConcurrentAnalyses < 1 {
+		errs = append(errs, fmt.Errorf("server.maxConcurrentAnalyses must be at least 1, got %d", app.Server.MaxConcurrentAnalyses))
+	}
+	if app.Analysis.MaxLinkChecks < 1 {
+		errs = append(errs, fmt.Errorf("analysis.maxLinkChecks must be at least 1, got %d", app.Analysis.MaxLinkChecks))
+	}
+	if app.Analysis.HostRate < 0 {
+		errs = append(errs, fmt.Errorf("analysis.hostRate must not be negative, got %v", app.Analysis.HostRate))
+	}
+	if app.Analysis.HostRate > 0 && app.Analysis.HostBurst < 1 {
+		errs = append(errs, fmt.Errorf("analysis.hostBurst must be at least 1, got %d", app.Analysis.HostBurst))
+	}
+	if app.Analysis.HostMaxWait < 0 {
+		errs = append(errs, fmt.Errorf("analysis.hostMaxWait must not be negative, got %s", app.Analysis.HostMaxWait))
+	}
+	if app.Analysis.MaxDocumentSize < 1 {
+		errs = append(errs, fmt.Errorf("analysis.maxDocumentSize must be at least 1, got %d", app.Analysis.MaxDocumentSize))
+	}
+	if app.Analysis.MaxDecompressedSize < 1 {
+		errs = append(errs, fmt.Errorf("analysis.maxDecompressedSize must be at least 1, got %d", app.Analysis.MaxDecompressedSize))
+	}
+	if app.Analysis.MaxProbeBytes < 1 {
+		errs = append(errs, fmt.Errorf("analysis.maxProbeBytes must be at least 1, got %d", app.Analysis.MaxProbeBytes))
+	}
+	for _, host := range app.Analysis.InternalHosts {
+		if host == "" || strings.ContainsAny(host, "/: \t") {
+			errs = append(errs, fmt.Errorf("analysis.internalHosts entry %q must be a host name such as docs.example.com", host))
+		}
+	}
+	if app.RateLimit.Rate < 0 {
+		errs = append(errs, fmt.Errorf("rateLimit.rate must not be negative, got %v", app.RateLimit.Rate))
+	}
+	if app.RateLimit.Rate > 0 && app.RateLimit.Burst < 1 {
+		errs = append(errs, fmt.Errorf("rateLimit.burst must be at least 1, got %d", app.RateLimit.Burst))
+	}
+	if strings.TrimSpace(app.Analysis.UserAgent) == "" {
+		errs = append(errs, errors.New("analysis.userAgent must not be empty"))
+	}
+
+	errs = append(errs, app.CORS.validate()...)
+
+	if app.Cache.Enabled {
+		if app.Cache.TTL <= 0 {
+			errs = append(errs, fmt.Errorf("cache.ttl must be positive, got %s", app.Cache.TTL))
+		}
+		if app.Cache.MaxEntries < 1 {
+			errs = append(errs, fmt.Errorf("cache.maxEntries must be at least 1, got %d", app.Cache.MaxEntries))
+		}
+	}
+
+	if app.Storage.Dir != "" {
+		info, err := os.Stat(app.Storage.Dir)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("storage.dir: %w", err))
+		} else if !info.IsDir() {
+			errs = append(errs, fmt.Errorf("storage.dir %s is not a directory", app.Storage.Dir))
+		}
+	}
+
+	if app.Auth.Enabled && len(app.Auth.Keys) == 0 && app.Storage.Dir == "" {
+		errs = append(errs, errors.New("auth.enabled requires auth.keys or storage.dir"))
+	}
+	errs = append(errs, validateAPIKeys("auth.keys", app.Auth.Keys)...)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// validate checks that every origin and method is well formed
+func (c CORSConfig) validate() []error {
+	var errs []error
+
+	if len(c.AllowedOrigins) == 0 {
+		errs = append(errs, errors.New("cors.allowedOrigins must list at least one origin"))
+	}
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" {
+			if c.AllowCredentials {
+				errs = append(errs, errors.New("cors.allowedOrigins must not contain * when cors.allowCredentials is set"))
+			}
+			continue
+		}
+		if strings.Count(origin, "*") > 1 {
+			errs = append(errs, fmt.Errorf("cors.allowedOrigins entry %q may contain at most one *", origin))
+			continue
+		}
+		u, err := url.Parse(strings.Replace(origin, "*", "wildcard", 1))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || (u.Path != "" && u.Path != "/") {
+			errs = append(errs, fmt.Errorf("cors.allowedOrigins entry %q must look like scheme://host[:port]", origin))
+		}
+	}
+
+	if len(c.AllowedMethods) == 0 {
+		errs = append(errs, errors.New("cors.allowedMethods must list at least one method"))
+	}
+	for _, method := range c.AllowedMethods {
+		if method != strings.ToUpper(method) || strings.ContainsAny(method, " \t,") {
+			errs = append(errs, fmt.Errorf("cors.allowedMethods entry %q must be an upper case HTTP method", method))
+		}
+	}
+
+	if c.MaxAge < 0 {
+		errs = append(errs, fmt.Errorf("cors.maxAge must not be negative, got %s", c.MaxAge))
+	}
+
+	return errs
+}
+
+// validateAPIKeys checks that every key has a unique name, a SHA-256 hex digest and sane quotas
+func validateAPIKeys(source string, keys []APIKeyConfig) []error {
+	var errs []error
+
+	names := make(map[string]bool)
+	for i, key := range keys {
+		if key.Name == "" {
+			errs = append(errs, fmt.Errorf("%s[%d].name must not be empty", source, i))
+		} else if names[key.Name] {
+			errs = append(errs, fmt.Errorf("%s[%d].name %q is used more than once", source, i, key.Name))
+		}
+		names[key.Name] = true
+
+		if _, err := hex.DecodeString(key.Hash); err != nil || len(key.Hash) != sha256.Size*2 {
+			errs = append(errs, fmt.Errorf("%s[%d].hash must be a SHA-256 hex digest", source, i))
+		}
+		if key.AnalysesPerDay < 0 {
+			errs = append(errs, fmt.Errorf("%s[%d].analysesPerDay must not be negative", source, i))
+		}
+		if key.LinkChecksPerAnalysis < 0 {
+			errs = append(errs, fmt.Errorf("%s[%d].linkChecksPerAnalysis must not be negative", source, i))
+		}
+	}
+
+	return errs
+}
+
+// loadAPIKeys combines the configured keys with the keys in the storage directory
+func (app *Config) loadAPIKeys() (map[string]APIKeyConfig, error) {
+	keys := append([]APIKeyConfig{}, app.Auth.Keys...)
+
+	if app.Storage.Dir != "" {
+		path := filepath.Join(app.Storage.Dir, apiKeysFile)
+		f, err := os.Open(path)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case err != nil:
+			return nil, fmt.Errorf("reading API keys: %w", err)
+		default:
+			defer f.Close()
+
+			var stored []APIKeyConfig
+			dec := yaml.NewDecoder(f)
+			dec.KnownFields(true)
+			if err := dec.Decode(&stored); err != nil && !errors.Is(err, io.EOF) {
+				return nil, fmt.Errorf("parsing API keys %s: %w", path, err)
+			}
+			if err := errors.Join(validateAPIKeys(path, stored)...); err != nil {
+				return nil, err
+			}
+			keys = append(keys, stored...)
+		}
+	}
+
+	byHash := make(map[string]APIKeyConfig, len(keys))
+	for _, key := range keys {
+		byHash[strings.ToLower(key.Hash)] = key
+	}
+
+	if app.Auth.Enabled && len(byHash) == 0 {
+		return nil, errors.New("auth is enabled but no API keys are configured")
+	}
+
+	return byHash, nil
+}
+
+// loadTrackerRules reads the tracker rule list that replaces the embedded one
+func loadTrackerRules(path string) ([]analyzer.TrackerRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading tracker rules: %w", err)
+	}
+	rules, err := analyzer.ParseTrackerRules(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing tracker rules %s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// options converts the analyzer settings into analyzer options
+func (c AnalysisConfig) options() analyzer.Options {
+	return analyzer.Options{
+		UserAgent:           c.UserAgent,
+		FetchTimeout:        c.FetchTimeout,
+		LinkCheckTimeout:    c.LinkCheckTimeout,
+		MaxLinkChecks:       c.MaxLinkChecks,
+		HostRate:            c.HostRate,
+		HostBurst:           c.HostBurst,
+		HostMaxWait:         c.HostMaxWait,
+		MaxDocumentSize:     c.MaxDocumentSize,
+		MaxDecompressedSize: c.MaxDecompressedSize,
+		MaxProbeBytes:       c.MaxProbeBytes,
+		InternalHosts:       c.InternalHosts,
+	}
+}
+
+// setup builds the services the handlers depend on from the loaded settings
+func (app *Config) setup() error {
+	specJSON, err := api.SpecJSON()
+	if err != nil {
+		return fmt.Errorf("converting OpenAPI spec: %w", err)
+	}
+	app.specJSON = specJSON
+
+	apiKeys, err := app.loadAPIKeys()
+	if err != nil {
+		return err
+	}
+	app.apiKeys = apiKeys
+	app.quotas = newQuotaTracker()
+	if app.RateLimit.Rate > 0 {
+		app.clients = ratelimit.New(rate.Limit(app.RateLimit.Rate), app.RateLimit.Burst)
+	}
+
+	opts := app.Analysis.options()
+	if app.Analysis.TrackerRules != "" {
+		rules, err := loadTrackerRules(app.Analysis.TrackerRules)
+		if err != nil {
+			return err
+		}
+		opts.TrackerRules = rules
+	}
+
+	app.analyze = analyzer.New(opts).AnalyzeURL
+	app.slots = make(chan struct{}, app.Server.MaxConcurrentAnalyses)
+	if app.Cache.Enabled {
+		app.cache = newResultCache(app.Cache.TTL, app.Cache.MaxEntries)
+	}
+
+	return nil
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeConfigFile writes content to a config file in a temporary directory
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return path
+}
+
+// envFunc returns a getenv function backed by env
+func envFunc(env map[string]string) func(string) string {
+	return func(key string) string {
+		return env[key]
+	}
+}
+
+func TestLoadConfigDefaults(t *testing.T) {
+	app, err := loadConfig(nil, envFunc(nil))
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+
+	if app.Port != "80" {
+		t.Errorf("Port = %v, want 80", app.Port)
+	}
+	if app.Server.MaxConcurrentAnalyses != 10 {
+		t.Errorf("MaxConcurrentAnalyses = %v, want 10", app.Server.MaxConcurrentAnalyses)
+	}
+}
+
+// Table-driven tests for the precedence of the config file, the environment and flags
+func TestLoadConfigSources(t *testing.T) {
+	yamlFile := writeConfigFile(t, "config.yaml", `
+port: "8081"
+analysis:
+  fetchTimeout: 20s
+  userAgent: from-file
+`)
+	jsonFile := writeConfigFile(t, "config.json", `{"port": "8082", "analysis": {"fetchTimeout": "15s"}}`)
+
+	tests := []struct {
+		name            string
+		args            []string
+		env             map[string]string
+		expectedPort    string
+		expectedTimeout time.Duration
+		expectedAgent   string
+		expectedOrigins []string
+	}{
+		{
+			name:            "YAMLFile",
+			args:            []string{"-config", yamlFile},
+			expectedPort:    "8081",
+			expectedTimeout: 20 * time.Second,
+			expectedAgent:   "from-file",
+		},
+		{
+			name:            "JSONFile",
+			env:             map[string]string{"ANALYZER_CONFIG": jsonFile},
+			expectedPort:    "8082",
+			expectedTimeout: 15 * time.Second,
+			expectedAgent:   "webpage-analyzer/1.0",
+		},
+		{
+			name:            "EnvOverridesFile",
+			args:            []string{"-config", yamlFile},
+			env:             map[string]string{"ANALYZER_PORT": "9000", "ANALYZER_CORS_ALLOWED_ORIGINS": "http://a.test, http://b.test"},
+			expectedPort:    "9000",
+			expectedTimeout: 20 * time.Second,
+			expectedAgent:   "from-file",
+			expectedOrigins: []string{"http://a.test", "http://b.test"},
+		},
+		{
+			name:            "FlagsOverrideEnv",
+			args:            []string{"-config", yamlFile, "-port", "9001", "-user-agent", "from-flag"},
+			env:             map[string]string{"ANALYZER_PORT": "9000", "ANALYZER_USER_AGENT": "from-env"},
+			expectedPort:    "9001",
+			expectedTimeout: 20 * time.Second,
+			expectedAgent:   "from-flag",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, err := loadConfig(tt.args, envFunc(tt.env))
+			if err != nil {
+				t.Fatalf("loadConfig() error = %v", err)
+			}
+			if app.Port != tt.expectedPort {
+				t.Errorf("Port = %v, want %v", app.Port, tt.expectedPort)
+			}
+			if app.Analysis.FetchTimeout != tt.expectedTimeout {
+				t.Errorf("FetchTimeout = %v, want %v", app.Analysis.FetchTimeout, tt.expectedTimeout)
+			}
+			if app.Analysis.UserAgent != tt.expectedAgent {
+				t.Errorf("UserAgent = %v, want %v", app.Analysis.UserAgent, tt.expectedAgent)
+			}
+			if tt.expectedOrigins != nil && strings.Join(app.CORS.AllowedOrigins, ",") != strings.Join(tt.expectedOrigins, ",") {
+				t.Errorf("AllowedOrigins = %v, want %v", app.CORS.AllowedOrigins, tt.expectedOrigins)
+			}
+		})
+	}
+}
+
+// Table-driven tests for configuration errors
+func TestLoadConfigErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		env      map[string]string
+		expected string
+	}{
+		{
+			name:     "InvalidPort",
+			args:     []string{"-port", "http"},
+			expected: "port must be a number",
+		},
+		{
+			name:     "InvalidDurationEnv",
+			env:      map[string]string{"ANALYZER_FETCH_TIMEOUT": "soon"},
+			expected: "invalid ANALYZER_FETCH_TIMEOUT",
+		},
+		{
+			name:     "NegativeTimeout",
+			args:     []string{"-link-check-timeout", "-1s"},
+			expected: "analysis.linkCheckTimeout must be positive",
+		},
+		{
+			name:     "AnalysisTimeoutNotBelowWriteTimeout",
+			args:     []string{"-analysis-timeout", "2m"},
+			expected: "analysis.timeout (2m0s) must be shorter than server.writeTimeout (2m0s)",
+		},
+		{
+			name:     "NegativeShutdownDelay",
+			args:     []string{"-shutdown-delay", "-1s"},
+			expected: "server.shutdownDelay must not be negative",
+		},
+		{
+			name:     "ZeroDocumentSize",
+			env:      map[string]string{"ANALYZER_MAX_DOCUMENT_SIZE": "0"},
+			expected: "analysis.maxDocumentSize must be at least 1, got 0",
+		},
+		{
+			name:     "InternalHostWithScheme",
+			env:      map[string]string{"ANALYZER_INTERNAL_HOSTS": "docs.example.com, https://cdn.example.net"},
+			expected: "analysis.internalHosts entry \"https://cdn.example.net\" must be a host name",
+		},
+		{
+			name:     "UnknownFileKey",
+			args:     []string{"-config", writeConfigFile(t, "typo.yaml", "prot: \"80\"\n")},
+			expected: "field prot not found",
+		},
+		{
+			name:     "MissingFile",
+			args:     []string{"-config", filepath.Join(t.TempDir(), "missing.yaml")},
+			expected: "reading config file",
+		},
+		{
+			name:     "MissingStorageDir",
+			args:     []string{"-storage-dir", filepath.Join(t.TempDir(), "missing")},
+			expected: "storage.dir",
+		},
+		{
+			name:     "InvalidOrigin",
+			args:     []string{"-cors-allowed-origins", "localhost:3000"},
+			expected: "cors.allowedOrigins entry \"localhost:3000\" must look like scheme://host[:port]",
+		},
+		{
+			name:     "WildcardOriginWithCredentials",
+			args:     []string{"-cors-allowed-origins", "*", "-cors-allow-credentials=true"},
+			expected: "cors.allowedOrigins must not contain * when cors.allowCredentials is set",
+		},
+		{
+			name:     "SeveralErrors",
+			args:     []string{"-max-concurrent-analyses", "0", "-max-link-checks", "0"},
+			expected: "server.maxConcurrentAnalyses must be at least 1, got 0\nanalysis.maxLinkChecks must be at least 1, got 0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadConfig(tt.args, envFunc(tt.env))
+			if err == nil {
+				t.Fatalf("loadConfig() error = nil, want %q", tt.expected)
+			}
+			if !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("loadConfig() error = %v, want %q", err, tt.expected)
+			}
+		})
+	}
+}
+
+// Table-driven tests for loading the tracker rule list during setup
+func TestSetupTrackerRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{name: "Valid", content: `[{"domain": "tracker.test", "category": "analytics", "company": "Test"}]`},
+		{name: "InvalidJSON", content: `{"domain": "tracker.test"}`, expected: "parsing tracker rules"},
+		{name: "UnknownCategory", content: `[{"domain": "tracker.test", "category": "spyware"}]`, expected: "unknown category"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := defaultConfig()
+			app.Analysis.TrackerRules = writeConfigFile(t, "trackers.json", tt.content)
+			err := app.setup()
+			if tt.expected == "" {
+				if err != nil {
+					t.Fatalf("setup() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("setup() error = %v, want %q", err, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"webpage-analyzer/api"
+
+	"gopkg.in/yaml.v3"
+)
+
+// openAPISpec is a decoded OpenAPI document
+type openAPISpec map[string]any
+
+func loadSpec(t *testing.T) openAPISpec {
+	t.Helper()
+
+	// nested objects take the type of the root, so decode into a plain map
+	var spec map[string]any
+	if err := yaml.Unmarshal(api.Spec, &spec); err != nil {
+		t.Fatalf("Failed to parse api.yaml: %v", err)
+	}
+	return openAPISpec(spec)
+}
+
+// lookup follows keys, such as "paths", "/v1/analyze", "post", through the spec
+func (s openAPISpec) lookup(keys ...string) (any, error) {
+	var node any = map[string]any(s)
+	for _, key := range keys {
+		m, ok := node.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s is not an object", key)
+		}
+		if node, ok = m[key]; !ok {
+			return nil, fmt.Errorf("%s not found", strings.Join(keys, " > "))
+		}
+	}
+	return s.resolve(node)
+}
+
+// resolve replaces a $ref object with the node it points to
+func (s openAPISpec) resolve(node any) (any, error) {
+	m, ok := node.(map[string]any)
+	if !ok {
+		return node, nil
+	}
+	ref, ok := m["$ref"].(string)
+	if !ok {
+		return node, nil
+	}
+	return s.lookup(strings.Split(strings.TrimPrefix(ref, "#/"), "/")...)
+}
+
+// responseSchema returns the JSON schema of the response documented for path, method and status
+func (s openAPISpec) responseSchema(path, method string, status int) (map[string]any, error) {
+	response, err := s.lookup("paths", path, method, "responses", strconv.Itoa(status))
+	if err != nil {
+		return nil, err
+	}
+	content, ok := response.(map[string]any)["content"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s %s %d documents no content", method, path, status)
+	}
+	media, ok := content["application/json"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s %s %d documents no JSON content", method, path, status)
+	}
+	schema, err := s.resolve(media["schema"])
+	if err != nil {
+		return nil, err
+	}
+	return schema.(map[string]any), nil
+}
+
+// validate checks value against the subset of JSON schema used in api.yaml and returns every violation
+func (s openAPISpec) validate(schema map[string]any, value any, at string) []string {
+	resolved, err := s.resolve(schema)
+	if err != nil {
+		return []string{fmt.Sprintf("%s: %v", at, err)}
+	}
+	schema = resolved.(map[string]any)
+
+	if value == nil {
+		if nullable, _ := schema["nullable"].(bool); nullable {
+			return nil
+		}
+		return []string{fmt.Sprintf("%s: null is not allowed", at)}
+	}
+
+	var errs []string
+
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, allowed := range enum {
+			if fmt.Sprint(allowed) == fmt.Sprint(value) {
+				found = true
+			}
+		}
+		if !found {
+			errs = append(errs, fmt.Sprintf("%s: %v is not one of %v", at, value, enum))
+		}
+	}
+
+	switch schema["type"] {
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return append(errs, fmt.Sprintf("%s: %T is not an object", at, value))
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		if required, ok := schema["required"].([]any); ok {
+			for _, name := range required {
+				if _, ok := obj[name.(string)]; !ok {
+					errs = append(errs, fmt.Sprintf("%s: missing required property %s", at, name))
+				}
+			}
+		}
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if property, ok := properties[key].(map[string]any); ok {
+				errs = append(errs, s.validate(property, obj[key], at+"."+key)...)
+				continue
+			}
+			switch additional := schema["additionalProperties"].(type) {
+			case bool:
+				if !additional {
+					errs = append(errs, fmt.Sprintf("%s: undocumented property %s", at, key))
+				}
+			case map[string]any:
+				errs = append(errs, s.validate(additional, obj[key], at+"."+key)...)
+			}
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return append(errs, fmt.Sprintf("%s: %T is not an array", at, value))
+		}
+		if itemSchema, ok := schema["items"].(map[string]any); ok {
+			for i, item := range items {
+				errs = append(errs, s.validate(itemSchema, item, fmt.Sprintf("%s[%d]", at, i))...)
+			}
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			errs = append(errs, fmt.Sprintf("%s: %T is not a string", at, value))
+		}
+	case "integer":
+		if n, ok := value.(float64); !ok || n != float64(int64(n)) {
+			errs = append(errs, fmt.Sprintf("%s: %v is not an integer", at, value))
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			errs = append(errs, fmt.Sprintf("%s: %T is not a number", at, value))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			errs = append(errs, fmt.Sprintf("%s: %T is not a boolean", at, value))
+		}
+	}
+
+	return errs
+}
+
+// newContractTestSite serves a page that links to itself, so analyses need no network access
+func newContractTestSite() *httptest.Server {
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(`<!DOCTYPE html><html><head><title>Contract</title></head><body>
+				<h1>Contract</h1>
+				<h2>Links</h2>
+				<a href="` + ts.URL + `/about">About</a>
+				<a href="` + ts.URL + `/missing">Missing</a>
+				<form action="/login" method="post">
+					<div><input type="text" name="username"></div>
+					<div><input type="password" name="password"></div>
+					<button type="submit">Sign in</button>
+				</form>
+			</body></html>`))
+		case "/about":
+			w.WriteHeader(http.StatusOK)
+		case "/data.json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"page": "not html"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	return ts
+}
+
+// Table-driven contract tests checking real responses against api.yaml
+func TestAPIContract(t *testing.T) {
+	spec := loadSpec(t)
+	site := newContractTestSite()
+	defer site.Close()
+
+	tests := []struct {
+		name     string
+		path     string
+		body     string
+		headers  map[string]string
+		auth     bool
+		expected int
+	}{
+		{
+			name:     "V1Analysis",
+			path:     "/v1/analyze",
+			body:     `{"url": "` + site.URL + `"}`,
+			expected: http.StatusOK,
+		},
+		{
+			name:     "V1InvalidURL",
+			path:     "/v1/analyze",
+			body:     `{"url": "not a url"}`,
+			expected: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "V1UnknownField",
+			path:     "/v1/analyze",
+			body:     `{"url": "` + site.URL + `", "action": "analyze"}`,
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "V1MissingAPIKey",
+			path:     "/v1/analyze",
+			body:     `{"url": "` + site.URL + `"}`,
+			auth:     true,
+			expected: http.StatusUnauthorized,
+		},
+		{
+			name:     "V1UnreachableSite",
+			path:     "/v1/analyze",
+			body:     `{"url": "` + site.URL + `/missing"}`,
+			expected: http.StatusBadGateway,
+		},
+		{
+			name:     "V1UnsupportedScheme",
+			path:     "/v1/analyze",
+			body:     `{"url": "ftp://www.example.com/file"}`,
+			expected: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "V1NotHTML",
+			path:     "/v1/analyze",
+			body:     `{"url": "` + site.URL + `/data.json"}`,
+			expected: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "LegacyAnalysis",
+			path:     "/",
+			body:     `{"url": "` + site.URL + `", "action": "analyze"}`,
+			expected: http.StatusOK,
+		},
+		{
+			name:     "LegacyInvalidJSON",
+			path:     "/",
+			body:     `{"url": `,
+			expected: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := defaultConfig()
+			if tt.auth {
+				app.Auth.Enabled = true
+				app.Auth.Keys = []APIKeyConfig{{Name: "contract", Hash: hashKey("secret")}}
+			}
+			if err := app.setup(); err != nil {
+				t.Fatalf("setup() error = %v", err)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+			app.routes().ServeHTTP(rr, req)
+
+			if rr.Code != tt.expected {
+				t.Fatalf("POST %s = %v, want %v: %s", tt.path, rr.Code, tt.expected, rr.Body.String())
+			}
+			if got := rr.Header().Get("Content-Type"); got != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", got)
+			}
+
+			schema, err := spec.responseSchema(tt.path, "post", rr.Code)
+			if err != nil {
+				t.Fatalf("api.yaml: %v", err)
+			}
+
+			var body any
+			if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+				t.Fatalf("response is not JSON: %v", err)
+			}
+			for _, violation := range spec.validate(schema, body, "response") {
+				t.Error(violation)
+			}
+		})
+	}
+}
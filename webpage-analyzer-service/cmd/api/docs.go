@@ -0,0 +1,34 @@
+package main
+
+import (
+	"net/http"
+	"webpage-analyzer/api"
+
+	swaggerFiles "github.com/swaggo/files"
+)
+
+// OpenAPIYAML serves the OpenAPI spec embedded in the binary
+func (app *Config) OpenAPIYAML(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(api.Spec)
+}
+
+// OpenAPIJSON serves the OpenAPI spec converted to JSON
+func (app *Config) OpenAPIJSON(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(app.specJSON)
+}
+
+// Docs serves the interactive API documentation
+func (app *Config) Docs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(api.DocsPage)
+}
+
+// docsAssets serves the Swagger UI scripts and styles the docs page loads
+func docsAssets() http.Handler {
+	return http.StripPrefix("/docs", http.FileServer(swaggerFiles.HTTP))
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"webpage-analyzer/api"
+)
+
+// Table-driven tests for the self-documentation routes
+func TestDocsRoutes(t *testing.T) {
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+		contains    string
+	}{
+		{
+			name:        "SpecYAML",
+			path:        "/openapi.yaml",
+			contentType: "application/yaml",
+			contains:    "/v1/analyze:",
+		},
+		{
+			name:        "SpecJSON",
+			path:        "/openapi.json",
+			contentType: "application/json",
+			contains:    `"/v1/analyze":`,
+		},
+		{
+			name:        "DocsPage",
+			path:        "/docs",
+			contentType: "text/html; charset=utf-8",
+			contains:    `url: "/openapi.yaml"`,
+		},
+		{
+			name:        "DocsPageTrailingSlash",
+			path:        "/docs/",
+			contentType: "text/html; charset=utf-8",
+			contains:    "swagger-ui-bundle.js",
+		},
+		{
+			name:        "SwaggerUIScript",
+			path:        "/docs/swagger-ui-bundle.js",
+			contentType: "text/javascript; charset=utf-8",
+			contains:    "SwaggerUIBundle",
+		},
+		{
+			name:        "SwaggerUIStyles",
+			path:        "/docs/swagger-ui.css",
+			contentType: "text/css; charset=utf-8",
+			contains:    ".swagger-ui",
+		},
+	}
+
+	app := defaultConfig()
+	if err := app.setup(); err != nil {
+		t.Fatalf("setup() error = %v", err)
+	}
+	routes := app.routes()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			routes.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rr.Code != http.StatusOK {
+				t.Fatalf("GET %s = %v, want %v", tt.path, rr.Code, http.StatusOK)
+			}
+			if got := rr.Header().Get("Content-Type"); got != tt.contentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.contentType)
+			}
+			if !strings.Contains(rr.Body.String(), tt.contains) {
+				t.Errorf("GET %s does not contain %q", tt.path, tt.contains)
+			}
+		})
+	}
+}
+
+func TestOpenAPIJSONMatchesYAML(t *testing.T) {
+	app := defaultConfig()
+	if err := app.setup(); err != nil {
+		t.Fatalf("setup() error = %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	app.routes().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	var fromJSON map[string]any
+	if err := json.Unmarshal(rr.Body.Bytes(), &fromJSON); err != nil {
+		t.Fatalf("/openapi.json is not JSON: %v", err)
+	}
+
+	want, err := api.SpecJSON()
+	if err != nil {
+		t.Fatalf("SpecJSON() error = %v", err)
+	}
+	if !bytes.Equal(rr.Body.Bytes(), want) {
+		t.Errorf("/openapi.json differs from the embedded api.yaml")
+	}
+	if fromJSON["openapi"] != "3.0.0" {
+		t.Errorf("openapi = %v, want 3.0.0", fromJSON["openapi"])
+	}
+}
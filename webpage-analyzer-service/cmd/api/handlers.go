@@ -1,6 +1,9 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
 	"webpage-analyzer/cmd/api/analyzer"
@@ -10,6 +13,33 @@ type AnalysisRequest struct {
 	URL string `json:"url"`
 }
 
+// legacyAnalysisResult is the result returned by the unversioned POST / route.
+// Its field names are frozen so existing clients keep working.
+type legacyAnalysisResult struct {
+	HTMLVersion          string         `json:"HTMLVersion"`
+	PageTitle            string         `json:"PageTitle"`
+	Headings             map[string]int `json:"Headings"`
+	NumInternalLinks     int            `json:"NumInternalLinks"`
+	NumExternalLinks     int            `json:"NumExternalLinks"`
+	NumInaccessibleLinks int            `json:"NumInaccessibleLinks"`
+	NumUncheckedLinks    int            `json:"NumUncheckedLinks"`
+	IsContainLoginForm   bool           `json:"IsContainLoginForm"`
+}
+
+func newLegacyAnalysisResult(result analyzer.AnalysisResult) legacyAnalysisResult {
+	return legacyAnalysisResult{
+		HTMLVersion:          result.HTMLVersion,
+		PageTitle:            result.PageTitle,
+		Headings:             result.Headings,
+		NumInternalLinks:     result.NumInternalLinks,
+		NumExternalLinks:     result.NumExternalLinks,
+		NumInaccessibleLinks: result.NumInaccessibleLinks,
+		NumUncheckedLinks:    result.NumUncheckedLinks,
+		IsContainLoginForm:   result.IsContainLoginForm,
+	}
+}
+
+// Analyzer serves the legacy POST / route
 func (app *Config) Analyzer(w http.ResponseWriter, r *http.Request) {
 	var requestPayload AnalysisRequest
 
@@ -19,15 +49,33 @@ func (app *Config) Analyzer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	parsedURL, err := url.ParseRequestURI(requestPayload.URL)
+	result, ok := app.runAnalysis(w, r, requestPayload)
+	if !ok {
+		return
+	}
+
+	payload := jsonResponse{
+		Error:          false,
+		StatusCode:     http.StatusOK,
+		Message:        "OK",
+		AnalysisResult: newLegacyAnalysisResult(result),
+	}
+
+	_ = app.writeJSON(w, http.StatusOK, payload)
+}
+
+// AnalyzeV1 serves POST /v1/analyze as documented in api.yaml
+func (app *Config) AnalyzeV1(w http.ResponseWriter, r *http.Request) {
+	var requestPayload AnalysisRequest
+
+	err := app.readStrictJSON(w, r, &requestPayload)
 	if err != nil {
 		app.errorJSON(w, err, http.StatusBadRequest)
 		return
 	}
 
-	result, err := analyzer.AnalyzeURL(parsedURL.String())
-	if err != nil {
-		app.errorJSON(w, err, http.StatusInternalServerError)
+	result, ok := app.runAnalysis(w, r, requestPayload)
+	if !ok {
 		return
 	}
 
@@ -40,3 +88,84 @@ func (app *Config) Analyzer(w http.ResponseWriter, r *http.Request) {
 
 	_ = app.writeJSON(w, http.StatusOK, payload)
 }
+
+// runAnalysis analyzes the requested URL, using the cache when possible. On failure it
+// writes the error response itself and reports false.
+func (app *Config) runAnalysis(w http.ResponseWriter, r *http.Request, requestPayload AnalysisRequest) (analyzer.AnalysisResult, bool) {
+	parsedURL, err := url.ParseRequestURI(requestPayload.URL)
+	if err != nil {
+		err = &analyzer.Error{Code: analyzer.CodeInvalidURL, Message: fmt.Sprintf("invalid URL: %v", err), Err: err}
+		app.errorJSON(w, err, analysisErrorStatus(err))
+		return analyzer.AnalysisResult{}, false
+	}
+
+	urlStr := parsedURL.String()
+	cacheKey := resultCacheKey(r.Context(), urlStr)
+
+	// the analysis has to finish before the server's write timeout drops the response
+	ctx, cancel := context.WithTimeout(r.Context(), app.Analysis.Timeout)
+	defer cancel()
+
+	if app.cache != nil {
+		if result, ok := app.cache.get(cacheKey); ok {
+			return result, true
+		}
+	}
+
+	// only valid requests that need an analysis count towards the daily quota
+	if !app.takeQuota(w, r) {
+		return analyzer.AnalysisResult{}, false
+	}
+
+	// wait for a free slot so only a limited number of analyses run at the same time;
+	// the wait ends with the analysis deadline at the latest
+	select {
+	case app.slots <- struct{}{}:
+		defer func() { <-app.slots }()
+	case <-ctx.Done():
+		// a client that went away gets no answer
+		if r.Context().Err() != nil {
+			return analyzer.AnalysisResult{}, false
+		}
+		app.errorJSON(w, errors.New("too many analyses in progress"), http.StatusServiceUnavailable)
+		return analyzer.AnalysisResult{}, false
+	}
+
+	result, err := app.analyze(ctx, urlStr)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = &analyzer.Error{
+			Code:    analyzer.CodeAnalysisTimeout,
+			Message: fmt.Sprintf("the analysis did not finish within %s", app.Analysis.Timeout),
+			Err:     ctx.Err(),
+		}
+	}
+	if err != nil {
+		var rateLimitErr *analyzer.RateLimitError
+		if errors.As(err, &rateLimitErr) {
+			w.Header().Set("Retry-After", retryAfterSeconds(rateLimitErr.RetryAfter))
+		}
+		app.errorJSON(w, err, analysisErrorStatus(err))
+		return analyzer.AnalysisResult{}, false
+	}
+
+	if app.cache != nil {
+		app.cache.set(cacheKey, result)
+	}
+
+	return result, true
+}
+
+// Readiness reports whether the service accepts new analyses. Unlike the /ping
+// heartbeat it turns unavailable as soon as the service starts shutting down.
+func (app *Config) Readiness(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain")
+
+	if !app.ready.Load() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte("not ready"))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("ready"))
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"webpage-analyzer/cmd/api/analyzer"
+)
+
+// Table-driven tests for Readiness
+func TestReadiness(t *testing.T) {
+	tests := []struct {
+		name     string
+		ready    bool
+		expected int
+	}{
+		{
+			name:     "Ready",
+			ready:    true,
+			expected: http.StatusOK,
+		},
+		{
+			name:     "ShuttingDown",
+			ready:    false,
+			expected: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := defaultConfig()
+			if err := app.setup(); err != nil {
+				t.Fatalf("setup() error = %v", err)
+			}
+			app.ready.Store(tt.ready)
+
+			rr := httptest.NewRecorder()
+			app.routes().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+			if rr.Code != tt.expected {
+				t.Errorf("GET /ready = %v, want %v", rr.Code, tt.expected)
+			}
+		})
+	}
+}
+
+// Table-driven tests for analysisErrorStatus
+func TestAnalysisErrorStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{
+			name:     "InvalidURL",
+			err:      &analyzer.Error{Code: analyzer.CodeInvalidURL},
+			expected: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "DNSFailure",
+			err:      &analyzer.Error{Code: analyzer.CodeDNSFailure},
+			expected: http.StatusBadGateway,
+		},
+		{
+			name:     "WrappedUpstreamStatus",
+			err:      fmt.Errorf("analysis: %w", &analyzer.Error{Code: analyzer.CodeUpstreamStatus, UpstreamStatus: 404}),
+			expected: http.StatusBadGateway,
+		},
+		{
+			name:     "Timeout",
+			err:      &analyzer.Error{Code: analyzer.CodeTimeout},
+			expected: http.StatusGatewayTimeout,
+		},
+		{
+			name:     "AnalysisTimeout",
+			err:      &analyzer.Error{Code: analyzer.CodeAnalysisTimeout},
+			expected: http.StatusGatewayTimeout,
+		},
+		{
+			name:     "NotHTML",
+			err:      &analyzer.Error{Code: analyzer.CodeNotHTML},
+			expected: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "RateLimited",
+			err:      &analyzer.RateLimitError{Host: "www.example.com"},
+			expected: http.StatusTooManyRequests,
+		},
+		{
+			name:     "Unclassified",
+			err:      errors.New("something broke"),
+			expected: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := analysisErrorStatus(tt.err); got != tt.expected {
+				t.Errorf("analysisErrorStatus(%v) = %v, want %v", tt.err, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAnalysisTimeout(t *testing.T) {
+	app := defaultConfig()
+	app.Analysis.Timeout = 50 * time.Millisecond
+	if err := app.setup(); err != nil {
+		t.Fatalf("setup() error = %v", err)
+	}
+	// the analysis runs until its context ends, like one stuck checking links
+	app.analyze = func(ctx context.Context, urlStr string) (analyzer.AnalysisResult, error) {
+		<-ctx.Done()
+		return analyzer.AnalysisResult{}, nil
+	}
+
+	rr := httptest.NewRecorder()
+	app.routes().ServeHTTP(rr, analyzeRequest(nil))
+
+	if rr.Code != http.StatusGatewayTimeout {
+		t.Errorf("POST / = %v, want %v", rr.Code, http.StatusGatewayTimeout)
+	}
+	if !strings.Contains(rr.Body.String(), `"code":"analysis_timeout"`) {
+		t.Errorf("POST / body = %s, want code analysis_timeout", rr.Body.String())
+	}
+}
+
+// Table-driven tests for waiting for a free analysis slot
+func TestAnalysisSlotWait(t *testing.T) {
+	tests := []struct {
+		name           string
+		clientGone     bool
+		expected       int
+		expectedAnswer bool
+	}{
+		{
+			name:           "BusyUntilDeadline",
+			expected:       http.StatusServiceUnavailable,
+			expectedAnswer: true,
+		},
+		{
+			name:       "ClientGone",
+			clientGone: true,
+			// the recorder reports 200 when nothing was written
+			expected: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := defaultConfig()
+			app.Server.MaxConcurrentAnalyses = 1
+			app.Analysis.Timeout = 50 * time.Millisecond
+			if err := app.setup(); err != nil {
+				t.Fatalf("setup() error = %v", err)
+			}
+			// another analysis holds the only slot
+			app.slots <- struct{}{}
+
+			req := analyzeRequest(nil)
+			if tt.clientGone {
+				ctx, cancel := context.WithCancel(req.Context())
+				cancel()
+				req = req.WithContext(ctx)
+			}
+			rr := httptest.NewRecorder()
+			app.routes().ServeHTTP(rr, req)
+
+			if rr.Code != tt.expected {
+				t.Errorf("POST / = %v, want %v", rr.Code, tt.expected)
+			}
+			if answered := rr.Body.Len() > 0; answered != tt.expectedAnswer {
+				t.Errorf("POST / answered = %v, want %v (%s)", answered, tt.expectedAnswer, rr.Body.String())
+			}
+		})
+	}
+}
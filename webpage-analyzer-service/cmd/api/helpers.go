@@ -4,25 +4,44 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 	"webpage-analyzer/cmd/api/analyzer"
 )
 
 type jsonResponse struct {
-	Error          bool                    `json:"error"`
-	StatusCode     int                     `json:"statusCode"`
-	Message        string                  `json:"message"`
-	Data           any                     `json:"data,omitempty"`
-	AnalysisResult analyzer.AnalysisResult `json:"analysisResult"`
+	Error      bool   `json:"error"`
+	StatusCode int    `json:"statusCode"`
+	Message    string `json:"message"`
+	// Code identifies why an analysis failed, see analyzer.ErrorCode
+	Code analyzer.ErrorCode `json:"code,omitempty"`
+	// UpstreamStatus is the status the analyzed page responded with
+	UpstreamStatus int `json:"upstreamStatus,omitempty"`
+	Data           any `json:"data,omitempty"`
+	AnalysisResult any `json:"analysisResult,omitempty"`
 }
 
 // readJSON tries to read the body of a request and converts it into JSON
 func (app *Config) readJSON(w http.ResponseWriter, r *http.Request, data any) error {
+	return app.decodeJSON(w, r, data, false)
+}
+
+// readStrictJSON works like readJSON but rejects fields data does not declare
+func (app *Config) readStrictJSON(w http.ResponseWriter, r *http.Request, data any) error {
+	return app.decodeJSON(w, r, data, true)
+}
+
+func (app *Config) decodeJSON(w http.ResponseWriter, r *http.Request, data any, strict bool) error {
 	maxBytes := 1048576 // one megabyte
 
 	r.Body = http.MaxBytesReader(w, r.Body, int64(maxBytes))
 
 	dec := json.NewDecoder(r.Body)
+	if strict {
+		dec.DisallowUnknownFields()
+	}
 	err := dec.Decode(data)
 	if err != nil {
 		return err
@@ -72,6 +91,34 @@ func (app *Config) errorJSON(w http.ResponseWriter, err error, status ...int) er
 	payload.Error = true
 	payload.StatusCode = statusCode
 	payload.Message = err.Error()
+	payload.Code = analyzer.ErrorCodeOf(err)
+
+	var analysisErr *analyzer.Error
+	if errors.As(err, &analysisErr) {
+		payload.UpstreamStatus = analysisErr.UpstreamStatus
+	}
 
 	return app.writeJSON(w, statusCode, payload)
 }
+
+// retryAfterSeconds formats d as the whole number of seconds for a Retry-After header
+func retryAfterSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// analysisErrorStatus maps an analysis error to the status code of the response
+func analysisErrorStatus(err error) int {
+	switch analyzer.ErrorCodeOf(err) {
+	case analyzer.CodeInvalidURL, analyzer.CodeNotHTML, analyzer.CodeTooLarge:
+		return http.StatusUnprocessableEntity
+	case analyzer.CodeDNSFailure, analyzer.CodeConnectionRefused, analyzer.CodeTLSError,
+		analyzer.CodeUpstreamStatus, analyzer.CodeFetchFailed:
+		return http.StatusBadGateway
+	case analyzer.CodeTimeout, analyzer.CodeAnalysisTimeout:
+		return http.StatusGatewayTimeout
+	case analyzer.CodeRateLimited:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
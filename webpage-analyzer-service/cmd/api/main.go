@@ -1,29 +1,89 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
-const webPort = "80"
-
-type Config struct{}
-
 func main() {
-	app := Config{}
+	app, err := loadConfig(os.Args[1:], os.Getenv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := app.setup(); err != nil {
+		log.Fatal(err)
+	}
 
-	log.Printf("Starting analyzer service on port %s\n", webPort)
+	// cancelled once the shutdown deadline has passed, aborting analyses that are still running
+	baseCtx, cancelBase := context.WithCancel(context.Background())
+	defer cancelBase()
 
 	// define http server
 	srv := &http.Server{
-		Addr:    fmt.Sprintf(":%s", webPort),
-		Handler: app.routes(),
+		Addr:              fmt.Sprintf(":%s", app.Port),
+		Handler:           app.routes(),
+		ReadHeaderTimeout: app.Server.ReadHeaderTimeout,
+		ReadTimeout:       app.Server.ReadTimeout,
+		WriteTimeout:      app.Server.WriteTimeout,
+		IdleTimeout:       app.Server.IdleTimeout,
+		BaseContext: func(net.Listener) context.Context {
+			return baseCtx
+		},
 	}
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// start the server
-	err := srv.ListenAndServe()
-	if err != nil {
-		log.Panic(err)
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Printf("Starting analyzer service on port %s\n", app.Port)
+		serverErr <- srv.ListenAndServe()
+	}()
+	app.ready.Store(true)
+
+	select {
+	case err := <-serverErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+		return
+	case <-ctx.Done():
 	}
+
+	// a second signal stops the service right away
+	stop()
+
+	log.Println("Shutting down analyzer service...")
+	app.ready.Store(false)
+
+	// keep serving while load balancers notice /ready is unavailable and drain the service
+	if app.Server.ShutdownDelay > 0 {
+		log.Printf("Draining for %s before closing listeners\n", app.Server.ShutdownDelay)
+		time.Sleep(app.Server.ShutdownDelay)
+	}
+
+	// wait for running analyses to finish, then force the rest to stop
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), app.Server.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Graceful shutdown did not complete: %v\n", err)
+		cancelBase()
+		_ = srv.Close()
+	}
+
+	log.Println("Analyzer service stopped")
 }
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"net/http"
+)
+
+// rateLimit rejects clients that request analyses faster than the configured rate.
+// Authenticated clients are limited per API key, everyone else per IP address.
+func (app *Config) rateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if app.clients == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if ok, retryAfter := app.clients.Allow(clientID(r)); !ok {
+			w.Header().Set("Retry-After", retryAfterSeconds(retryAfter))
+			app.errorJSON(w, errors.New("too many requests"), http.StatusTooManyRequests)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientID identifies the client of r by its API key name, or by its IP address when unauthenticated
+func clientID(r *http.Request) string {
+	if apiKey, ok := apiKeyFromContext(r.Context()); ok {
+		return "key:" + apiKey.Name
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"webpage-analyzer/cmd/api/analyzer"
+)
+
+// newRateLimitTestApp returns an app allowing burst analyses per client and a stub analyzer
+func newRateLimitTestApp(t *testing.T, burst int) *Config {
+	t.Helper()
+
+	app := defaultConfig()
+	app.RateLimit.Rate = 1.0 / 60
+	app.RateLimit.Burst = burst
+	if err := app.setup(); err != nil {
+		t.Fatalf("setup() error = %v", err)
+	}
+	app.analyze = func(ctx context.Context, urlStr string) (analyzer.AnalysisResult, error) {
+		return analyzer.AnalysisResult{}, nil
+	}
+	return app
+}
+
+// Table-driven tests for rateLimit
+func TestRateLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		clients  []string
+		expected []int
+	}{
+		{
+			name:     "WithinBurst",
+			clients:  []string{"192.0.2.1:1000", "192.0.2.1:1001"},
+			expected: []int{http.StatusOK, http.StatusOK},
+		},
+		{
+			name:     "OverBurst",
+			clients:  []string{"192.0.2.1:1000", "192.0.2.1:1001", "192.0.2.1:1002"},
+			expected: []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			name:     "ClientsLimitedSeparately",
+			clients:  []string{"192.0.2.1:1000", "192.0.2.1:1001", "192.0.2.2:1000"},
+			expected: []int{http.StatusOK, http.StatusOK, http.StatusOK},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newRateLimitTestApp(t, 2)
+			routes := app.routes()
+
+			for i, client := range tt.clients {
+				req := analyzeRequest(nil)
+				req.RemoteAddr = client
+
+				rr := httptest.NewRecorder()
+				routes.ServeHTTP(rr, req)
+
+				if rr.Code != tt.expected[i] {
+					t.Errorf("request %d from %s = %v, want %v", i+1, client, rr.Code, tt.expected[i])
+				}
+				if rr.Code == http.StatusTooManyRequests && rr.Header().Get("Retry-After") == "" {
+					t.Errorf("request %d from %s has no Retry-After header", i+1, client)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimitPerAPIKey(t *testing.T) {
+	app := newRateLimitTestApp(t, 1)
+	app.Auth.Enabled = true
+	app.apiKeys = map[string]APIKeyConfig{
+		hashKey("first"):  {Name: "first"},
+		hashKey("second"): {Name: "second"},
+	}
+	routes := app.routes()
+
+	// both keys share an IP address but are limited separately
+	for _, tt := range []struct {
+		key      string
+		expected int
+	}{
+		{"first", http.StatusOK},
+		{"second", http.StatusOK},
+		{"first", http.StatusTooManyRequests},
+	} {
+		rr := httptest.NewRecorder()
+		routes.ServeHTTP(rr, analyzeRequest(map[string]string{"X-API-Key": tt.key}))
+		if rr.Code != tt.expected {
+			t.Errorf("request with key %s = %v, want %v", tt.key, rr.Code, tt.expected)
+		}
+	}
+}
+
+func TestAnalyzerHostRateLimit(t *testing.T) {
+	app := newRateLimitTestApp(t, 5)
+	app.analyze = func(ctx context.Context, urlStr string) (analyzer.AnalysisResult, error) {
+		return analyzer.AnalysisResult{}, &analyzer.RateLimitError{Host: "www.example.com", RetryAfter: 1500 * time.Millisecond}
+	}
+
+	rr := httptest.NewRecorder()
+	app.routes().ServeHTTP(rr, analyzeRequest(nil))
+
+	if rr.Code != http.StatusTooManyRequests {
+		t.Errorf("POST / = %v, want %v", rr.Code, http.StatusTooManyRequests)
+	}
+	if got := rr.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After = %q, want %q", got, "2")
+	}
+}
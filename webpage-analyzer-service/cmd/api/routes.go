@@ -13,17 +13,36 @@ func (app *Config) routes() http.Handler {
 
 	// specify who is allowed to connect
 	mux.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"https://*", "http://*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
-		AllowCredentials: true,
-		MaxAge:           300,
+		AllowedOrigins:   app.CORS.AllowedOrigins,
+		AllowedMethods:   app.CORS.AllowedMethods,
+		AllowedHeaders:   app.CORS.AllowedHeaders,
+		ExposedHeaders:   []string{"Link", "Retry-After"},
+		AllowCredentials: app.CORS.AllowCredentials,
+		MaxAge:           int(app.CORS.MaxAge.Seconds()),
 	}))
 
 	mux.Use(middleware.Heartbeat("/ping"))
 
-	mux.Post("/", app.Analyzer)
+	mux.Get("/ready", app.Readiness)
+
+	// the API describes itself
+	mux.Get("/openapi.yaml", app.OpenAPIYAML)
+	mux.Get("/openapi.json", app.OpenAPIJSON)
+	mux.Get("/docs", app.Docs)
+	mux.Get("/docs/", app.Docs)
+	mux.Handle("/docs/*", docsAssets())
+
+	// analyses require an API key when auth is enabled and are rate limited per client;
+	// runAnalysis takes the daily quota of the key
+	mux.Group(func(mux chi.Router) {
+		mux.Use(app.authenticate)
+		mux.Use(app.rateLimit)
+
+		mux.Post("/v1/analyze", app.AnalyzeV1)
+
+		// legacy route, superseded by /v1/analyze
+		mux.Post("/", app.Analyzer)
+	})
 
 	return mux
 }
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Table-driven tests for CORS preflight requests
+func TestCORSPreflight(t *testing.T) {
+	tests := []struct {
+		name           string
+		origins        []string
+		credentials    bool
+		origin         string
+		method         string
+		headers        string
+		expectedOrigin string
+		expectedCreds  string
+	}{
+		{
+			name:           "DefaultFrontendOrigin",
+			origin:         "http://localhost",
+			method:         http.MethodPost,
+			headers:        "Content-Type",
+			expectedOrigin: "http://localhost",
+		},
+		{
+			name:    "DefaultRejectsOtherOrigin",
+			origin:  "https://evil.example",
+			method:  http.MethodPost,
+			headers: "Content-Type",
+		},
+		{
+			name:    "DefaultRejectsOtherPort",
+			origin:  "http://localhost:3000",
+			method:  http.MethodPost,
+			headers: "Content-Type",
+		},
+		{
+			name:    "RejectedMethod",
+			origin:  "http://localhost",
+			method:  http.MethodDelete,
+			headers: "Content-Type",
+		},
+		{
+			name:    "RejectedHeader",
+			origin:  "http://localhost",
+			method:  http.MethodPost,
+			headers: "X-CSRF-Token",
+		},
+		{
+			name:           "ConfiguredWildcardSubdomain",
+			origins:        []string{"https://*.example.com"},
+			origin:         "https://app.example.com",
+			method:         http.MethodPost,
+			expectedOrigin: "https://app.example.com",
+		},
+		{
+			name:    "ConfiguredWildcardRejectsOtherDomain",
+			origins: []string{"https://*.example.com"},
+			origin:  "https://example.org",
+			method:  http.MethodPost,
+		},
+		{
+			name:           "ConfiguredCredentials",
+			origins:        []string{"https://app.example.com"},
+			credentials:    true,
+			origin:         "https://app.example.com",
+			method:         http.MethodPost,
+			expectedOrigin: "https://app.example.com",
+			expectedCreds:  "true",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := defaultConfig()
+			if tt.origins != nil {
+				app.CORS.AllowedOrigins = tt.origins
+			}
+			app.CORS.AllowCredentials = tt.credentials
+			if err := app.setup(); err != nil {
+				t.Fatalf("setup() error = %v", err)
+			}
+
+			req := httptest.NewRequest(http.MethodOptions, "/", nil)
+			req.Header.Set("Origin", tt.origin)
+			req.Header.Set("Access-Control-Request-Method", tt.method)
+			if tt.headers != "" {
+				req.Header.Set("Access-Control-Request-Headers", tt.headers)
+			}
+
+			rr := httptest.NewRecorder()
+			app.routes().ServeHTTP(rr, req)
+
+			if got := rr.Header().Get("Access-Control-Allow-Origin"); got != tt.expectedOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.expectedOrigin)
+			}
+			if got := rr.Header().Get("Access-Control-Allow-Credentials"); got != tt.expectedCreds {
+				t.Errorf("Access-Control-Allow-Credentials = %q, want %q", got, tt.expectedCreds)
+			}
+		})
+	}
+}
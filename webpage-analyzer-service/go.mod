@@ -5,5 +5,9 @@ go 1.22
 require (
 	github.com/go-chi/chi/v5 v5.0.12
 	github.com/go-chi/cors v1.2.1
+	github.com/swaggo/files v1.0.1
 	golang.org/x/net v0.25.0
+	golang.org/x/text v0.15.0
+	golang.org/x/time v0.5.0
+	gopkg.in/yaml.v3 v3.0.1
 )
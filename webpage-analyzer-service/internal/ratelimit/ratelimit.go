@@ -0,0 +1,70 @@
+// Package ratelimit keeps token buckets per key, such as a client or a host
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// IdleTTL is how long an unused limiter is kept around
+const IdleTTL = 10 * time.Minute
+
+// Limiters keeps a token bucket for every key
+type Limiters struct {
+	mu       sync.Mutex
+	limit    rate.Limit
+	burst    int
+	limiters map[string]*entry
+	now      func() time.Time
+}
+
+type entry struct {
+	limiter  *rate.Limiter
+	lastUsed time.Time
+}
+
+// New returns limiters allowing limit events per second and bursts of burst events for every key
+func New(limit rate.Limit, burst int) *Limiters {
+	return &Limiters{
+		limit:    limit,
+		burst:    burst,
+		limiters: make(map[string]*entry),
+		now:      time.Now,
+	}
+}
+
+// Get returns the limiter of key, dropping limiters that have been idle for longer than IdleTTL
+func (l *Limiters) Get(key string) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	e, ok := l.limiters[key]
+	if !ok {
+		for k, idle := range l.limiters {
+			if now.Sub(idle.lastUsed) > IdleTTL {
+				delete(l.limiters, k)
+			}
+		}
+		e = &entry{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.limiters[key] = e
+	}
+	e.lastUsed = now
+	return e.limiter
+}
+
+// Allow takes a token from the bucket of key. When the bucket is empty it
+// reports false and how long until the next token is available.
+func (l *Limiters) Allow(key string) (bool, time.Duration) {
+	limiter := l.Get(key)
+
+	now := l.now()
+	reservation := limiter.ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
@@ -0,0 +1,56 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Table-driven tests for Allow
+func TestAllow(t *testing.T) {
+	tests := []struct {
+		name     string
+		keys     []string
+		expected []bool
+	}{
+		{name: "WithinBurst", keys: []string{"a", "a"}, expected: []bool{true, true}},
+		{name: "OverBurst", keys: []string{"a", "a", "a"}, expected: []bool{true, true, false}},
+		{name: "KeysLimitedSeparately", keys: []string{"a", "a", "b"}, expected: []bool{true, true, true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiters := New(1.0/60, 2)
+			for i, key := range tt.keys {
+				ok, retryAfter := limiters.Allow(key)
+				if ok != tt.expected[i] {
+					t.Errorf("Allow(%q) #%d = %v, want %v", key, i+1, ok, tt.expected[i])
+				}
+				if !ok && retryAfter <= 0 {
+					t.Errorf("Allow(%q) #%d retry after = %v, want a positive delay", key, i+1, retryAfter)
+				}
+			}
+		})
+	}
+}
+
+func TestIdleLimitersDropped(t *testing.T) {
+	limiters := New(rate.Every(time.Minute), 1)
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	limiters.now = func() time.Time { return start }
+	limiters.Get("idle")
+	limiters.Get("busy")
+
+	limiters.now = func() time.Time { return start.Add(IdleTTL / 2) }
+	limiters.Get("busy")
+
+	// a new key drops the limiters idle for longer than IdleTTL
+	limiters.now = func() time.Time { return start.Add(IdleTTL + time.Minute) }
+	limiters.Get("new")
+
+	if got := len(limiters.limiters); got != 2 {
+		t.Errorf("limiters kept = %v, want 2", got)
+	}
+}